@@ -0,0 +1,79 @@
+// Package apperrors classifies errors by what went wrong, so the HTTP layer
+// can pick a status code without knowing where the error came from.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	// KindUnknown is an unclassified error, usually a request the business
+	// rules rejected.
+	KindUnknown Kind = iota
+	KindNotFound
+	KindConflict
+	KindForbidden
+	KindValidation
+	KindInternal
+)
+
+type Error struct {
+	Kind    Kind
+	Message string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error returns the message, or the underlying error when there is none.
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: KindConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Kind: KindForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(format string, args ...interface{}) error {
+	return &Error{Kind: KindValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Internal marks err as a failure the client cannot fix. Its text is logged
+// but not shown to the client.
+func Internal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: KindInternal, Err: err}
+}
+
+// Wrap classifies err as kind with message, keeping err as the cause.
+func Wrap(kind Kind, message string, err error) error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// KindOf returns the kind of the first *Error in the chain of err.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindUnknown
+}
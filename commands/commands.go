@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"synapsis-backend/configs"
+	"synapsis-backend/logger"
+	"synapsis-backend/migrations"
+	"synapsis-backend/notifiers"
+	"synapsis-backend/repositories"
+	"synapsis-backend/usecases"
+
+	"gorm.io/gorm"
+)
+
+const usage = `usage: synapsis-backend [flags] [command]
+
+commands:
+  serve              start the HTTP server (default)
+  migrate <command>  manage the database schema: up, down, status, create
+  seed               add demo categories, products and users
+  user create-admin  create an admin account
+  orders expire      cancel unpaid orders and return their stock
+  stock recount      compare product stock with the stock ledger
+
+flags:
+  -env-file      optional file with environment variables (default .env)
+  -port          HTTP port
+  -database-url  PostgreSQL connection string
+  -timezone      timezone timestamps are stored in
+
+Run a command with -h to see its own flags.`
+
+// Run runs the command named by the arguments left after the flags. It
+// logs JSON lines to stdout, including what is still written with the
+// standard log package.
+func Run(cfg *configs.Config) error {
+	log := logger.New(os.Stdout, cfg.Log.Level)
+	slog.SetDefault(log)
+
+	args := cfg.Args
+	if len(args) == 0 {
+		return Serve(cfg, log, nil)
+	}
+
+	switch args[0] {
+	case "serve":
+		return Serve(cfg, log, args[1:])
+	case "migrate":
+		return Migrate(cfg, log, args[1:])
+	case "seed":
+		return Seed(cfg, log, args[1:])
+	case "user":
+		if len(args) > 1 && args[1] == "create-admin" {
+			return CreateAdmin(cfg, log, args[2:])
+		}
+	case "orders":
+		if len(args) > 1 && args[1] == "expire" {
+			return ExpireOrders(cfg, log, args[2:])
+		}
+	case "stock":
+		if len(args) > 1 && args[1] == "recount" {
+			return RecountStock(cfg, log, args[2:])
+		}
+	}
+	return errors.New(usage)
+}
+
+// connect opens the database and refuses to go on while migrations are
+// pending. Migrations are only run explicitly with `migrate up`.
+func connect(cfg *configs.Config, log *slog.Logger) (*gorm.DB, error) {
+	db, err := configs.ConnectDB(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrator.EnsureCurrent(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// newInventoryUsecase builds the inventory usecase the same way the server
+// does, so stock changed by a command raises the same alerts.
+func newInventoryUsecase(cfg *configs.Config, db *gorm.DB, log *slog.Logger) usecases.InventoryUsecase {
+	notifier := notifiers.NewLogNotifier(log)
+	alertNotifier := notifier
+	if cfg.Inventory.LowStockWebhookURL != "" {
+		alertNotifier = notifiers.NewWebhookNotifier(cfg.Inventory.LowStockWebhookURL, cfg.Log.RequestIDHeader)
+	}
+
+	return usecases.NewInventoryUsecase(
+		repositories.NewStockMovementRepository(db),
+		repositories.NewStockAlertRepository(db),
+		repositories.NewProductRepository(db),
+		repositories.NewWishlistRepository(db),
+		notifier,
+		alertNotifier,
+		log,
+	)
+}
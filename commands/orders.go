@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"synapsis-backend/configs"
+	"synapsis-backend/repositories"
+	"synapsis-backend/usecases"
+	"time"
+)
+
+// ExpireOrders cancels the orders left unpaid for too long and returns
+// their stock.
+func ExpireOrders(cfg *configs.Config, log *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("orders expire", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 24*time.Hour, "expire orders unpaid for longer than this")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *olderThan <= 0 {
+		return errors.New("-older-than must be positive")
+	}
+
+	db, err := connect(cfg, log)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	allocationStrategy, err := usecases.NewAllocationStrategy(cfg.Inventory.AllocationStrategy)
+	if err != nil {
+		return err
+	}
+	orderUsecase := usecases.NewOrderUsecase(
+		repositories.NewOrderRepository(db),
+		repositories.NewCartRepository(db),
+		repositories.NewProductRepository(db),
+		repositories.NewOrderDetailRepository(db),
+		repositories.NewWarehouseRepository(db),
+		repositories.NewUserRepository(db),
+		repositories.NewTransactor(db),
+		newInventoryUsecase(cfg, db, log),
+		allocationStrategy,
+		log,
+	)
+
+	expired, err := orderUsecase.ExpireOrders(ctx, *olderThan)
+	for _, orderID := range expired {
+		fmt.Println("Expired order", orderID)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d order(s) expired\n", len(expired))
+	return nil
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"synapsis-backend/configs"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/models"
+	"synapsis-backend/repositories"
+	"synapsis-backend/usecases"
+	"time"
+)
+
+// seedPassword is the password of every demo user.
+const seedPassword = "synapsis123"
+
+type seedUser struct {
+	FullName string
+	Email    string
+	Role     string
+}
+
+var seedUsers = []seedUser{
+	{"Admin Synapsis", "admin@synapsis.local", models.RoleAdmin},
+	{"Budi Santoso", "budi@synapsis.local", models.RoleUser},
+	{"Siti Rahayu", "siti@synapsis.local", models.RoleUser},
+}
+
+var seedProducts = map[string][]dtos.ProductInput{
+	"Pakaian": {
+		{Name: "Kemeja Flanel", Description: "Kemeja flanel lengan panjang", Price: 189000, Stock: 40, ReorderThreshold: 5, Status: true},
+		{Name: "Kaos Polos", Description: "Kaos katun combed 30s", Price: 79000, Stock: 120, ReorderThreshold: 20, Status: true},
+	},
+	"Elektronik": {
+		{Name: "Earphone Bluetooth", Description: "Earphone nirkabel dengan case pengisi daya", Price: 349000, Stock: 25, ReorderThreshold: 5, Status: true},
+		{Name: "Power Bank 10000 mAh", Description: "Power bank dengan fast charging", Price: 259000, Stock: 30, ReorderThreshold: 5, Status: true},
+	},
+	"Buku": {
+		{Name: "Belajar Go", Description: "Panduan pemrograman Go untuk pemula", Price: 125000, Stock: 15, ReorderThreshold: 3, Status: true},
+	},
+}
+
+// Seed adds demo categories, products and users. Records that already exist
+// are left alone, so it can be run more than once.
+func Seed(cfg *configs.Config, log *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := connect(cfg, log)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	userRepository := repositories.NewUserRepository(db)
+	categoryRepository := repositories.NewCategoryRepository(db)
+	productRepository := repositories.NewProductRepository(db)
+	categoryUsecase := usecases.NewCategoryUsecase(categoryRepository)
+	productUsecase := usecases.NewProductUsecase(productRepository, repositories.NewReviewRepository(db), repositories.NewTransactor(db), newInventoryUsecase(cfg, db, log))
+
+	for _, seed := range seedUsers {
+		user, _ := userRepository.UserGetByEmail(ctx, seed.Email)
+		if user.ID > 0 {
+			fmt.Println("User", seed.Email, "exists")
+			continue
+		}
+
+		password, err := helpers.HashPassword(seedPassword)
+		if err != nil {
+			return err
+		}
+		verifiedAt := time.Now()
+		_, err = userRepository.UserCreate(ctx, models.User{
+			FullName:        seed.FullName,
+			Email:           seed.Email,
+			Password:        password,
+			Citizen:         "Indonesia",
+			Role:            seed.Role,
+			EmailVerifiedAt: &verifiedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("create user %s: %w", seed.Email, err)
+		}
+		fmt.Printf("Created %s %s with password %s\n", seed.Role, seed.Email, seedPassword)
+	}
+
+	categories, _, err := categoryRepository.GetAllCategorys(ctx, 1, 1000)
+	if err != nil {
+		return err
+	}
+	categoryIDs := map[string]uint{}
+	for _, category := range categories {
+		categoryIDs[category.Category] = category.ID
+	}
+
+	for _, categoryName := range []string{"Pakaian", "Elektronik", "Buku"} {
+		categoryID, ok := categoryIDs[categoryName]
+		if !ok {
+			category, err := categoryUsecase.CreateCategory(ctx, &dtos.CategoryInput{Category: categoryName})
+			if err != nil {
+				return fmt.Errorf("create category %s: %w", categoryName, err)
+			}
+			categoryID = category.CategoryID
+			fmt.Println("Created category", categoryName)
+		}
+
+		products, _, err := productRepository.GetAllProducts(ctx, 1, 1000, int(categoryID))
+		if err != nil {
+			return err
+		}
+		productNames := map[string]bool{}
+		for _, product := range products {
+			productNames[product.Name] = true
+		}
+
+		for _, product := range seedProducts[categoryName] {
+			if productNames[product.Name] {
+				fmt.Println("Product", product.Name, "exists")
+				continue
+			}
+
+			product.CategoryID = categoryID
+			if _, err := productUsecase.CreateProduct(ctx, &product); err != nil {
+				return fmt.Errorf("create product %s: %w", product.Name, err)
+			}
+			fmt.Println("Created product", product.Name)
+		}
+	}
+
+	return nil
+}
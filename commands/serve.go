@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"synapsis-backend/configs"
+	"synapsis-backend/controllers"
+	"synapsis-backend/helpers"
+	"synapsis-backend/metrics"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/migrations"
+	"synapsis-backend/repositories"
+	"synapsis-backend/routes"
+	"synapsis-backend/workers"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	echoSwagger "github.com/swaggo/echo-swagger"
+)
+
+// Serve starts the HTTP server and the background workers. On SIGINT or
+// SIGTERM it stops accepting connections, lets in-flight requests and the
+// workers finish within the shutdown timeout and closes the database pool.
+func Serve(cfg *configs.Config, log *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := connect(cfg, log)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	e := echo.New()
+	// Echo's banner and startup line are not JSON, the start is logged below.
+	e.HideBanner = true
+	e.HidePort = true
+	e.Validator = helpers.NewValidator()
+	e.HTTPErrorHandler = helpers.NewHTTPErrorHandler(log)
+
+	e.Use(metrics.Middleware())
+	e.Use(middlewares.RequestID(cfg.Log.RequestIDHeader))
+	e.Use(middlewares.RequestLogger(log))
+	e.Use(middlewares.Recover(log))
+	e.Use(middleware.BodyLimit(cfg.Server.BodyLimit))
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:  cfg.CORS.AllowOrigins,
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, cfg.Log.RequestIDHeader},
+		ExposeHeaders: []string{cfg.Log.RequestIDHeader},
+	}))
+
+	var backgroundWorkers workers.Group
+	if err := routes.Init(e, db, cfg, log, &backgroundWorkers); err != nil {
+		return err
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	healthController := controllers.NewHealthController(repositories.NewHealthRepository(db), migrator, &backgroundWorkers, log)
+	e.GET("/healthz", healthController.Liveness)
+	e.GET("/readyz", healthController.Readiness)
+
+	if err := prometheus.Register(collectors.NewDBStatsCollector(sqlDB, "synapsis")); err != nil {
+		return err
+	}
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
+	e.GET("/swagger/*", echoSwagger.WrapHandler)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	backgroundWorkers.Run(workerCtx)
+
+	server := &http.Server{
+		Addr:              cfg.Server.Address(),
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	log.Info("server started", slog.String("address", server.Addr))
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- e.StartServer(server)
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	case <-ctx.Done():
+	}
+	stop()
+
+	healthController.SetShuttingDown()
+	if cfg.Server.ShutdownDelay > 0 {
+		log.Info("shutting down, failing readiness", slog.String("delay", cfg.Server.ShutdownDelay.String()))
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	log.Info("shutting down, waiting for in-flight requests", slog.String("timeout", cfg.Server.ShutdownTimeout.String()))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to drain in-flight requests", slog.Any("error", err))
+	}
+
+	stopWorkers()
+	if err := backgroundWorkers.Wait(shutdownCtx); err != nil {
+		log.Error("background workers did not stop in time", slog.Any("error", err))
+	}
+
+	log.Info("server stopped")
+	return nil
+}
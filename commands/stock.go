@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"synapsis-backend/configs"
+	"text/tabwriter"
+)
+
+// RecountStock reports the products whose stock does not match the stock
+// ledger or their warehouses, and reconciles the ledger with -reconcile.
+func RecountStock(cfg *configs.Config, log *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("stock recount", flag.ContinueOnError)
+	reconcile := flags.Bool("reconcile", false, "record an adjustment movement for every product whose ledger is off")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := connect(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	recounts, err := newInventoryUsecase(cfg, db, log).RecountStock(context.Background(), *reconcile)
+	if len(recounts) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PRODUCT\tNAME\tSTOCK\tLEDGER\tIN WAREHOUSES\tRECONCILED")
+		for _, recount := range recounts {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%t\n",
+				recount.ProductID, recount.ProductName, recount.Stock,
+				recount.LedgerStock, recount.AssignedStock, recount.Reconciled)
+		}
+		w.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(recounts) == 0 {
+		fmt.Println("Stock matches the ledger and the warehouses")
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/configs"
+	"synapsis-backend/helpers"
+	"synapsis-backend/models"
+	"synapsis-backend/repositories"
+	"time"
+)
+
+// CreateAdmin creates an admin account with a verified email. Without
+// -password a random one is generated and printed.
+func CreateAdmin(cfg *configs.Config, log *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("user create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin (required)")
+	fullName := flags.String("name", "Admin", "full name of the admin")
+	password := flags.String("password", "", "password, at least 8 characters (default random)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *email == "" {
+		return errors.New("usage: user create-admin -email <email> [-name <name>] [-password <password>]")
+	}
+	generated := *password == ""
+	if generated {
+		token, err := helpers.GenerateRandomToken(12)
+		if err != nil {
+			return err
+		}
+		*password = token
+	}
+	if len(*password) < 8 {
+		return apperrors.Validation("Password must be at least 8 characters")
+	}
+
+	db, err := connect(cfg, log)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	userRepository := repositories.NewUserRepository(db)
+
+	user, _ := userRepository.UserGetByEmail(ctx, *email)
+	if user.ID > 0 {
+		return apperrors.Conflict("Email already used")
+	}
+
+	hashed, err := helpers.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+	verifiedAt := time.Now()
+	user, err = userRepository.UserCreate(ctx, models.User{
+		FullName:        *fullName,
+		Email:           *email,
+		Password:        hashed,
+		Citizen:         "Indonesia",
+		Role:            models.RoleAdmin,
+		EmailVerifiedAt: &verifiedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created admin %s with ID %d\n", user.Email, user.ID)
+	if generated {
+		fmt.Println("Password:", *password)
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package configs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/labstack/gommon/bytes"
+)
+
+// Config is the application configuration. It is loaded once at startup by
+// Load and passed down to whatever needs it.
+type Config struct {
+	App       AppConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	CORS      CORSConfig
+	Inventory InventoryConfig
+	Log       LogConfig
+	OIDC      OIDCConfig
+
+	// Timezone is the IANA name of the zone timestamps are stored in.
+	Timezone string
+	Location *time.Location
+
+	// MailDir is where mails are written. Empty means they go to the log.
+	MailDir string
+
+	// Args are the command line arguments left after the flags.
+	Args []string
+}
+
+type AppConfig struct {
+	Name string
+	URL  string
+}
+
+type ServerConfig struct {
+	Port              int
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout is how long in-flight requests and background workers
+	// get to finish after SIGTERM. Cloud Run kills the container 10 seconds
+	// after SIGTERM, so it defaults to less than that.
+	ShutdownTimeout time.Duration
+	// ShutdownDelay is how long /readyz fails before the server stops
+	// accepting connections, for load balancers that poll readiness.
+	ShutdownDelay time.Duration
+	// BodyLimit is the largest accepted request body, such as 4M.
+	BodyLimit string
+}
+
+// Address is the listen address of the HTTP server.
+func (s ServerConfig) Address() string {
+	return fmt.Sprintf(":%d", s.Port)
+}
+
+type DatabaseConfig struct {
+	URL             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// SlowQueryThreshold is how long a query may take before it is logged
+	// as slow. 0 turns the slow query log off.
+	SlowQueryThreshold time.Duration
+}
+
+type JWTConfig struct {
+	// Secret signs HS256 tokens when KeysDir is not set.
+	Secret    string
+	KeysDir   string
+	ActiveKID string
+	TokenTTL  time.Duration
+}
+
+type CORSConfig struct {
+	AllowOrigins []string
+}
+
+type LogConfig struct {
+	Level slog.Level
+	// RequestIDHeader carries the request ID. An ID sent by the client or a
+	// proxy in it is kept, so one ID can follow a request across services.
+	RequestIDHeader string
+}
+
+type OIDCConfig struct {
+	// Providers are the OpenID Connect providers users can sign in with.
+	Providers []OIDCProviderConfig
+}
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes default to openid, email and profile.
+	Scopes []string
+}
+
+type InventoryConfig struct {
+	LowStockWebhookURL    string
+	LowStockCheckInterval time.Duration
+	AllocationStrategy    string
+}
+
+// Load reads the configuration. Values come from, in order of precedence,
+// the command line flags in args, the environment, the optional .env file
+// and the defaults. The result is validated before it is returned.
+func Load(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("synapsis-backend", flag.ContinueOnError)
+	envFile := flags.String("env-file", ".env", "optional file with environment variables")
+	port := flags.Int("port", 0, "HTTP port (PORT)")
+	databaseURL := flags.String("database-url", "", "PostgreSQL connection string (DATABASE_URL)")
+	timezone := flags.String("timezone", "", "timezone timestamps are stored in (APP_TIMEZONE)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Variables already set in the environment win over the file.
+	if err := godotenv.Load(*envFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("load %s: %w", *envFile, err)
+	}
+
+	env := envReader{}
+	cfg := &Config{
+		App: AppConfig{
+			Name: env.String("APP_NAME", "Synapsis"),
+			URL:  strings.TrimRight(env.String("APP_URL", "http://localhost:8080"), "/"),
+		},
+		Server: ServerConfig{
+			Port:              env.Int("PORT", 8080),
+			ReadTimeout:       env.Duration("SERVER_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: env.Duration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      env.Duration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       env.Duration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			ShutdownTimeout:   env.Duration("SERVER_SHUTDOWN_TIMEOUT", 8*time.Second),
+			ShutdownDelay:     env.Duration("SERVER_SHUTDOWN_DELAY", 0),
+			BodyLimit:         env.String("SERVER_BODY_LIMIT", "4M"),
+		},
+		Database: DatabaseConfig{
+			URL:                env.String("DATABASE_URL", ""),
+			MaxOpenConns:       env.Int("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:       env.Int("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime:    env.Duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime:    env.Duration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			SlowQueryThreshold: env.Duration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+		},
+		JWT: JWTConfig{
+			Secret:    env.String("SECRET_JWT", ""),
+			KeysDir:   env.String("JWT_KEYS_DIR", ""),
+			ActiveKID: env.String("JWT_ACTIVE_KID", ""),
+			TokenTTL:  env.Duration("JWT_TOKEN_TTL", 24*time.Hour),
+		},
+		CORS: CORSConfig{
+			AllowOrigins: env.List("CORS_ALLOW_ORIGINS", []string{
+				"http://localhost:8080",
+				"https://rest-api-7qon5jxieq-et.a.run.app",
+			}),
+		},
+		Inventory: InventoryConfig{
+			LowStockWebhookURL:    env.String("LOW_STOCK_WEBHOOK_URL", ""),
+			LowStockCheckInterval: env.Duration("LOW_STOCK_CHECK_INTERVAL", 15*time.Minute),
+			AllocationStrategy:    env.String("WAREHOUSE_ALLOCATION_STRATEGY", ""),
+		},
+		Log: LogConfig{
+			Level:           env.Level("LOG_LEVEL", slog.LevelInfo),
+			RequestIDHeader: env.String("REQUEST_ID_HEADER", "X-Request-ID"),
+		},
+		OIDC: OIDCConfig{
+			Providers: env.OIDCProviders(),
+		},
+		Timezone: env.String("APP_TIMEZONE", "Asia/Jakarta"),
+		MailDir:  env.String("MAIL_DIR", ""),
+		Args:     flags.Args(),
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "database-url":
+			cfg.Database.URL = *databaseURL
+		case "timezone":
+			cfg.Timezone = *timezone
+		}
+	})
+
+	errs := env.errs
+	errs = append(errs, cfg.validate()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %s", strings.Join(errs, "; "))
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) validate() []string {
+	var errs []string
+
+	if cfg.Server.Port < 1 || cfg.Server.Port > 65535 {
+		errs = append(errs, fmt.Sprintf("PORT %d is out of range", cfg.Server.Port))
+	}
+	if cfg.Server.ReadTimeout <= 0 || cfg.Server.ReadHeaderTimeout <= 0 || cfg.Server.WriteTimeout <= 0 || cfg.Server.IdleTimeout <= 0 {
+		errs = append(errs, "SERVER_*_TIMEOUT must be positive")
+	}
+	if cfg.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, "SERVER_SHUTDOWN_TIMEOUT must be positive")
+	}
+	if cfg.Server.ShutdownDelay < 0 {
+		errs = append(errs, "SERVER_SHUTDOWN_DELAY must not be negative")
+	}
+	if limit, err := bytes.Parse(cfg.Server.BodyLimit); err != nil || limit <= 0 {
+		errs = append(errs, fmt.Sprintf("SERVER_BODY_LIMIT %q is not a size such as 512K or 4M", cfg.Server.BodyLimit))
+	}
+	if cfg.Database.URL == "" {
+		errs = append(errs, "DATABASE_URL is required")
+	}
+	if cfg.Database.MaxOpenConns > 0 && cfg.Database.MaxIdleConns > cfg.Database.MaxOpenConns {
+		errs = append(errs, "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	}
+	if cfg.Database.SlowQueryThreshold < 0 {
+		errs = append(errs, "DB_SLOW_QUERY_THRESHOLD must not be negative")
+	}
+	if cfg.JWT.KeysDir == "" && cfg.JWT.Secret == "" {
+		errs = append(errs, "SECRET_JWT is required when JWT_KEYS_DIR is not set")
+	}
+	if cfg.JWT.KeysDir != "" && cfg.JWT.ActiveKID == "" {
+		errs = append(errs, "JWT_ACTIVE_KID is required when JWT_KEYS_DIR is set")
+	}
+	if cfg.JWT.TokenTTL <= 0 {
+		errs = append(errs, "JWT_TOKEN_TTL must be positive")
+	}
+	if cfg.Inventory.LowStockCheckInterval <= 0 {
+		errs = append(errs, "LOW_STOCK_CHECK_INTERVAL must be positive")
+	}
+	names := map[string]bool{}
+	for _, provider := range cfg.OIDC.Providers {
+		if names[provider.Name] {
+			errs = append(errs, fmt.Sprintf("OIDC_PROVIDERS lists %q twice", provider.Name))
+		}
+		names[provider.Name] = true
+
+		prefix := oidcPrefix(provider.Name)
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			errs = append(errs, fmt.Sprintf("oidc provider %q needs %sISSUER, %sCLIENT_ID and %sREDIRECT_URL", provider.Name, prefix, prefix, prefix))
+		}
+	}
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("APP_TIMEZONE %q is not a known timezone", cfg.Timezone))
+	}
+	cfg.Location = location
+
+	return errs
+}
+
+// envReader reads typed environment variables and collects the ones that
+// cannot be parsed, so every mistake is reported at once.
+type envReader struct {
+	errs []string
+}
+
+func (r *envReader) String(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (r *envReader) Int(key string, fallback int) int {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("%s %q is not a number", key, value))
+		return fallback
+	}
+	return n
+}
+
+func (r *envReader) Duration(key string, fallback time.Duration) time.Duration {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("%s %q is not a duration such as 30s or 15m", key, value))
+		return fallback
+	}
+	return d
+}
+
+// Level reads a log level: debug, info, warn or error.
+func (r *envReader) Level(key string, fallback slog.Level) slog.Level {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("%s %q is not one of debug, info, warn or error", key, value))
+		return fallback
+	}
+	return level
+}
+
+// List reads a comma separated list.
+func (r *envReader) List(key string, fallback []string) []string {
+	value := r.String(key, "")
+	if value == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// OIDCProviders reads the providers listed in OIDC_PROVIDERS, e.g.
+// "google,mock". Each provider NAME is configured with OIDC_NAME_ISSUER,
+// OIDC_NAME_CLIENT_ID, OIDC_NAME_CLIENT_SECRET, OIDC_NAME_REDIRECT_URL and
+// the optional space separated OIDC_NAME_SCOPES.
+func (r *envReader) OIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range r.List("OIDC_PROVIDERS", nil) {
+		prefix := oidcPrefix(name)
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(r.String(prefix+"ISSUER", ""), "/"),
+			ClientID:     r.String(prefix+"CLIENT_ID", ""),
+			ClientSecret: r.String(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  r.String(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(r.String(prefix+"SCOPES", "")),
+		})
+	}
+	return providers
+}
+
+func oidcPrefix(name string) string {
+	return "OIDC_" + strings.ToUpper(name) + "_"
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyController interface {
+	GetAllAPIKeys(c echo.Context) error
+	CreateAPIKey(c echo.Context) error
+	RevokeAPIKey(c echo.Context) error
+}
+
+type apiKeyController struct {
+	apiKeyUsecase usecases.APIKeyUsecase
+}
+
+func NewAPIKeyController(apiKeyUsecase usecases.APIKeyUsecase) APIKeyController {
+	return &apiKeyController{apiKeyUsecase}
+}
+
+func (c *apiKeyController) GetAllAPIKeys(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+
+	apiKeys, count, err := c.apiKeyUsecase.GetAllAPIKeys(ctx.Request().Context(), page, limit)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get API keys", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get API keys",
+			apiKeys,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *apiKeyController) CreateAPIKey(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	adminId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var apiKeyInput dtos.APIKeyInput
+	if err := ctx.Bind(&apiKeyInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&apiKeyInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	apiKey, err := c.apiKeyUsecase.CreateAPIKey(ctx.Request().Context(), adminId, apiKeyInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to create API key", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully created API key",
+			apiKey,
+		),
+	)
+}
+
+func (c *apiKeyController) RevokeAPIKey(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	err := c.apiKeyUsecase.RevokeAPIKey(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to revoke API key", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully revoked API key",
+			nil,
+		),
+	)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"synapsis-backend/dtos"
 	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
 	"synapsis-backend/usecases"
 
 	"github.com/labstack/echo/v4"
@@ -16,6 +17,11 @@ type CartController interface {
 	CreateCart(c echo.Context) error
 	UpdateCart(c echo.Context) error
 	DeleteCart(c echo.Context) error
+	GetCartSummary(c echo.Context) error
+	GetUserCart(c echo.Context) error
+	AddCartItems(c echo.Context) error
+	ReplaceCart(c echo.Context) error
+	ClearCart(c echo.Context) error
 }
 
 type cartController struct {
@@ -47,16 +53,9 @@ func (c *cartController) GetAllCarts(ctx echo.Context) error {
 		user_id = 0
 	}
 
-	carts, count, err := c.cartUsecase.GetAllCarts(page, limit, user_id)
+	carts, count, err := c.cartUsecase.GetAllCarts(ctx.Request().Context(), page, limit, user_id)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get all cart",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get all cart", err)
 	}
 
 	return ctx.JSON(
@@ -74,17 +73,10 @@ func (c *cartController) GetAllCarts(ctx echo.Context) error {
 
 func (c *cartController) GetCartByID(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
-	cart, err := c.cartUsecase.GetCartByID(uint(id))
+	cart, err := c.cartUsecase.GetCartByID(ctx.Request().Context(), uint(id))
 
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get cart by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get cart by id", err)
 	}
 
 	return ctx.JSON(
@@ -101,21 +93,16 @@ func (c *cartController) GetCartByID(ctx echo.Context) error {
 func (c *cartController) CreateCart(ctx echo.Context) error {
 	var cartDTO dtos.CartInput
 	if err := ctx.Bind(&cartDTO); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&cartDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
 	}
 
-	cart, err := c.cartUsecase.CreateCart(&cartDTO)
+	cart, err := c.cartUsecase.CreateCart(ctx.Request().Context(), &cartDTO)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to created a cart",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to created a cart", err)
 	}
 
 	return ctx.JSON(
@@ -132,62 +119,243 @@ func (c *cartController) UpdateCart(ctx echo.Context) error {
 
 	var cartInput dtos.CartInput
 	if err := ctx.Bind(&cartInput); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&cartInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
 	}
 
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	cart, err := c.cartUsecase.GetCartByID(uint(id))
+	cart, err := c.cartUsecase.GetCartByID(ctx.Request().Context(), uint(id))
 	if cart.CartID == 0 {
+		return helpers.NewHTTPError("Failed to get cart by id", err)
+	}
+
+	cartResp, err := c.cartUsecase.UpdateCart(ctx.Request().Context(), uint(id), cartInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to updated a cart", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully updated cart",
+			cartResp,
+		),
+	)
+}
+
+func (c *cartController) DeleteCart(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	err := c.cartUsecase.DeleteCart(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to delete cart", err)
+	}
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully deleted cart",
+			nil,
+		),
+	)
+}
+
+func (c *cartController) GetCartSummary(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get cart by id",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
-	cartResp, err := c.cartUsecase.UpdateCart(uint(id), cartInput)
+	summary, err := c.cartUsecase.GetCartSummary(ctx.Request().Context(), userId)
 	if err != nil {
+		return helpers.NewHTTPError("Failed to get cart summary", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully get cart summary",
+			summary,
+		),
+	)
+}
+
+func (c *cartController) AddCartItems(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to updated a cart",
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	var cartInput dtos.CartBatchInput
+	if err := ctx.Bind(&cartInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&cartInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	carts, err := c.cartUsecase.AddCartItems(ctx.Request().Context(), userId, cartInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to add products to cart", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully added products to cart",
+			carts,
+		),
+	)
+}
+
+func (c *cartController) ReplaceCart(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var cartInput dtos.CartBatchInput
+	if err := ctx.Bind(&cartInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&cartInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	carts, err := c.cartUsecase.ReplaceCart(ctx.Request().Context(), userId, cartInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to replace cart", err)
+	}
+
 	return ctx.JSON(
 		http.StatusOK,
 		helpers.NewResponse(
 			http.StatusOK,
-			"Successfully updated cart",
-			cartResp,
+			"Successfully replaced cart",
+			carts,
 		),
 	)
 }
 
-func (c *cartController) DeleteCart(ctx echo.Context) error {
-	id, _ := strconv.Atoi(ctx.Param("id"))
+func (c *cartController) ClearCart(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
 
-	err := c.cartUsecase.DeleteCart(uint(id))
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	err = c.cartUsecase.ClearCart(ctx.Request().Context(), userId)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to clear cart", err)
 	}
 	return ctx.JSON(
 		http.StatusOK,
 		helpers.NewResponse(
 			http.StatusOK,
-			"Successfully deleted cart",
+			"Successfully cleared cart",
 			nil,
 		),
 	)
 }
+
+func (c *cartController) GetUserCart(ctx echo.Context) error {
+	userID, _ := strconv.Atoi(ctx.Param("id"))
+
+	summary, err := c.cartUsecase.GetUserCart(ctx.Request().Context(), uint(userID))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get user cart", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully get user cart",
+			summary,
+		),
+	)
+}
@@ -41,16 +41,9 @@ func (c *categoryController) GetAllCategorys(ctx echo.Context) error {
 		limit = 10
 	}
 
-	categorys, count, err := c.categoryUsecase.GetAllCategorys(page, limit)
+	categorys, count, err := c.categoryUsecase.GetAllCategorys(ctx.Request().Context(), page, limit)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get all category",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get all category", err)
 	}
 
 	return ctx.JSON(
@@ -68,17 +61,10 @@ func (c *categoryController) GetAllCategorys(ctx echo.Context) error {
 
 func (c *categoryController) GetCategoryByID(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
-	category, err := c.categoryUsecase.GetCategoryByID(uint(id))
+	category, err := c.categoryUsecase.GetCategoryByID(ctx.Request().Context(), uint(id))
 
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get category by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get category by id", err)
 	}
 
 	return ctx.JSON(
@@ -95,21 +81,16 @@ func (c *categoryController) GetCategoryByID(ctx echo.Context) error {
 func (c *categoryController) CreateCategory(ctx echo.Context) error {
 	var categoryDTO dtos.CategoryInput
 	if err := ctx.Bind(&categoryDTO); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
 	}
 
-	category, err := c.categoryUsecase.CreateCategory(&categoryDTO)
+	if err := ctx.Validate(&categoryDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	category, err := c.categoryUsecase.CreateCategory(ctx.Request().Context(), &categoryDTO)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to created a category",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to created a category", err)
 	}
 
 	return ctx.JSON(
@@ -126,35 +107,23 @@ func (c *categoryController) UpdateCategory(ctx echo.Context) error {
 
 	var categoryInput dtos.CategoryInput
 	if err := ctx.Bind(&categoryInput); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&categoryInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
 	}
 
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	category, err := c.categoryUsecase.GetCategoryByID(uint(id))
+	category, err := c.categoryUsecase.GetCategoryByID(ctx.Request().Context(), uint(id))
 	if category.CategoryID == 0 {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get category by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get category by id", err)
 	}
 
-	categoryResp, err := c.categoryUsecase.UpdateCategory(uint(id), categoryInput)
+	categoryResp, err := c.categoryUsecase.UpdateCategory(ctx.Request().Context(), uint(id), categoryInput)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to updated a category",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to updated a category", err)
 	}
 
 	return ctx.JSON(
@@ -170,11 +139,9 @@ func (c *categoryController) UpdateCategory(ctx echo.Context) error {
 func (c *categoryController) DeleteCategory(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	err := c.categoryUsecase.DeleteCategory(uint(id))
+	err := c.categoryUsecase.DeleteCategory(ctx.Request().Context(), uint(id))
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Failed to delete category", err)
 	}
 	return ctx.JSON(
 		http.StatusOK,
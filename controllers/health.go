@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"synapsis-backend/dtos"
+	"synapsis-backend/migrations"
+	"synapsis-backend/repositories"
+	"synapsis-backend/workers"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// readinessTimeout bounds the database queries of a readiness check.
+const readinessTimeout = 2 * time.Second
+
+// HealthController serves the probes of the container orchestrator. Like
+// the JWKS they are bare JSON, the status code is what the orchestrator
+// looks at.
+type HealthController struct {
+	healthRepo   repositories.HealthRepository
+	migrator     *migrations.Migrator
+	workers      *workers.Group
+	log          *slog.Logger
+	shuttingDown int32
+}
+
+func NewHealthController(healthRepo repositories.HealthRepository, migrator *migrations.Migrator, workers *workers.Group, log *slog.Logger) *HealthController {
+	return &HealthController{healthRepo: healthRepo, migrator: migrator, workers: workers, log: log}
+}
+
+// SetShuttingDown makes the readiness check fail from now on, so no new
+// traffic is sent while in-flight requests drain.
+func (c *HealthController) SetShuttingDown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+// Liveness reports that the process is up and serving requests.
+func (c *HealthController) Liveness(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, dtos.HealthResponse{Status: "ok"})
+}
+
+// Readiness reports whether the service can take traffic: the database
+// answers, the schema is current and every background worker is running.
+func (c *HealthController) Readiness(ctx echo.Context) error {
+	if atomic.LoadInt32(&c.shuttingDown) == 1 {
+		return ctx.JSON(http.StatusServiceUnavailable, dtos.HealthResponse{Status: "shutting_down"})
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx.Request().Context(), readinessTimeout)
+	defer cancel()
+
+	checks := map[string]dtos.HealthCheck{
+		"database":   c.checkDatabase(checkCtx),
+		"migrations": c.checkMigrations(checkCtx),
+		"workers":    c.checkWorkers(),
+	}
+
+	response := dtos.HealthResponse{Status: "ok", Checks: checks}
+	for _, check := range checks {
+		if check.Status != "ok" {
+			response.Status = "unavailable"
+			return ctx.JSON(http.StatusServiceUnavailable, response)
+		}
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *HealthController) checkDatabase(ctx context.Context) dtos.HealthCheck {
+	start := time.Now()
+	err := c.healthRepo.Ping(ctx)
+	check := dtos.HealthCheck{
+		Status:    "ok",
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		// The driver error names the database host and user, keep it in the log.
+		c.log.ErrorContext(ctx, "readiness: database ping failed", slog.Any("error", err))
+		check.Status = "fail"
+		check.Error = "database is unreachable"
+	}
+	return check
+}
+
+func (c *HealthController) checkMigrations(ctx context.Context) dtos.HealthCheck {
+	pending, err := c.migrator.WithContext(ctx).Pending()
+	if err != nil {
+		c.log.ErrorContext(ctx, "readiness: migration check failed", slog.Any("error", err))
+		return dtos.HealthCheck{Status: "fail", Error: "could not read schema_migrations"}
+	}
+
+	check := dtos.HealthCheck{Status: "ok"}
+	for _, migration := range pending {
+		check.Status = "fail"
+		check.Pending = append(check.Pending, fmt.Sprintf("%d_%s", migration.Version, migration.Name))
+	}
+	return check
+}
+
+func (c *HealthController) checkWorkers() dtos.HealthCheck {
+	check := dtos.HealthCheck{Status: "ok"}
+	for _, status := range c.workers.Statuses() {
+		if !status.Running {
+			check.Status = "fail"
+		}
+		check.Workers = append(check.Workers, dtos.WorkerStatusResponse{
+			Name:      status.Name,
+			Running:   status.Running,
+			LastRunAt: status.LastRunAt,
+			LastError: status.LastError,
+		})
+	}
+	return check
+}
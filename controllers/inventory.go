@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InventoryController interface {
+	GetStockHistory(c echo.Context) error
+	AdjustStock(c echo.Context) error
+	GetStockAlerts(c echo.Context) error
+}
+
+type inventoryController struct {
+	inventoryUsecase usecases.InventoryUsecase
+}
+
+func NewInventoryController(inventoryUsecase usecases.InventoryUsecase) InventoryController {
+	return &inventoryController{inventoryUsecase}
+}
+
+func (c *inventoryController) GetStockHistory(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+
+	movements, count, err := c.inventoryUsecase.GetStockHistory(ctx.Request().Context(), page, limit, uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get stock history", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get stock history",
+			movements,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *inventoryController) AdjustStock(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var adjustmentInput dtos.StockAdjustmentInput
+	if err := ctx.Bind(&adjustmentInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&adjustmentInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	movement, err := c.inventoryUsecase.AdjustStock(ctx.Request().Context(), userId, uint(id), adjustmentInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to adjust stock", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully adjusted stock",
+			movement,
+		),
+	)
+}
+
+func (c *inventoryController) GetStockAlerts(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+	status := ctx.QueryParam("status")
+
+	alerts, count, err := c.inventoryUsecase.GetStockAlerts(ctx.Request().Context(), page, limit, status)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get stock alerts", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get stock alerts",
+			alerts,
+			page,
+			limit,
+			count,
+		),
+	)
+}
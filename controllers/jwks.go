@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"net/http"
+	"synapsis-backend/middlewares"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetJWKS serves the public keys our tokens are signed with. It is returned
+// as a bare JSON Web Key Set, not wrapped in the usual response, so standard
+// JWT libraries can read it.
+func GetJWKS(ctx echo.Context) error {
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return ctx.JSON(http.StatusOK, middlewares.JWKS())
+}
@@ -3,8 +3,10 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"synapsis-backend/apperrors"
 	"synapsis-backend/dtos"
 	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
 	"synapsis-backend/usecases"
 
 	"github.com/labstack/echo/v4"
@@ -13,6 +15,7 @@ import (
 type OrderController interface {
 	GetAllOrders(c echo.Context) error
 	GetOrderByID(c echo.Context) error
+	GetUserOrders(c echo.Context) error
 	CreateOrder(c echo.Context) error
 	UpdateOrder(c echo.Context) error
 	DeleteOrder(c echo.Context) error
@@ -43,16 +46,9 @@ func (c *orderController) GetAllOrders(ctx echo.Context) error {
 	}
 	status := ctx.QueryParam("status")
 
-	orders, count, err := c.orderUsecase.GetAllOrders(page, limit, status)
+	orders, count, err := c.orderUsecase.GetAllOrders(ctx.Request().Context(), page, limit, status)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get all order",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get all order", err)
 	}
 
 	return ctx.JSON(
@@ -70,17 +66,10 @@ func (c *orderController) GetAllOrders(ctx echo.Context) error {
 
 func (c *orderController) GetOrderByID(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
-	order, err := c.orderUsecase.GetOrderByID(uint(id))
+	order, err := c.orderUsecase.GetOrderByID(ctx.Request().Context(), uint(id))
 
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get order by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get order by id", err)
 	}
 
 	return ctx.JSON(
@@ -97,21 +86,16 @@ func (c *orderController) GetOrderByID(ctx echo.Context) error {
 func (c *orderController) CreateOrder(ctx echo.Context) error {
 	var orderDTO dtos.OrderInput
 	if err := ctx.Bind(&orderDTO); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
 	}
 
-	order, err := c.orderUsecase.CreateOrder(&orderDTO)
+	if err := ctx.Validate(&orderDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	order, err := c.orderUsecase.CreateOrder(ctx.Request().Context(), &orderDTO)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to created a order",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to created a order", err)
 	}
 
 	return ctx.JSON(
@@ -125,24 +109,50 @@ func (c *orderController) CreateOrder(ctx echo.Context) error {
 }
 
 func (c *orderController) Checkout(ctx echo.Context) error {
-	var orderDTO dtos.OrderInputCheckout
-	if err := ctx.Bind(&orderDTO); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
 	}
-	order, err := c.orderUsecase.Checkout(&orderDTO)
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to created a order",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	var orderDTO dtos.OrderInputCheckout
+	if err := ctx.Bind(&orderDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	// The order is made for the caller; user_id may be left out of the body.
+	if orderDTO.UserID != 0 && orderDTO.UserID != userId {
+		return helpers.NewHTTPError("Failed to created a order", apperrors.Forbidden("user_id does not match the token"))
+	}
+	orderDTO.UserID = userId
+
+	if err := ctx.Validate(&orderDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	order, err := c.orderUsecase.Checkout(ctx.Request().Context(), &orderDTO)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to created a order", err)
+	}
+
 	return ctx.JSON(
 		http.StatusCreated,
 		helpers.NewResponse(
@@ -157,35 +167,23 @@ func (c *orderController) UpdateOrder(ctx echo.Context) error {
 
 	var orderInput dtos.OrderInput
 	if err := ctx.Bind(&orderInput); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&orderInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
 	}
 
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	order, err := c.orderUsecase.GetOrderByID(uint(id))
+	order, err := c.orderUsecase.GetOrderByID(ctx.Request().Context(), uint(id))
 	if order.OrderID == 0 {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get order by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get order by id", err)
 	}
 
-	orderResp, err := c.orderUsecase.UpdateOrder(uint(id), orderInput)
+	orderResp, err := c.orderUsecase.UpdateOrder(ctx.Request().Context(), uint(id), orderInput)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to updated a order",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to updated a order", err)
 	}
 
 	return ctx.JSON(
@@ -201,11 +199,9 @@ func (c *orderController) UpdateOrder(ctx echo.Context) error {
 func (c *orderController) DeleteOrder(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	err := c.orderUsecase.DeleteOrder(uint(id))
+	err := c.orderUsecase.DeleteOrder(ctx.Request().Context(), uint(id))
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Failed to delete order", err)
 	}
 	return ctx.JSON(
 		http.StatusOK,
@@ -216,3 +212,35 @@ func (c *orderController) DeleteOrder(ctx echo.Context) error {
 		),
 	)
 }
+
+func (c *orderController) GetUserOrders(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+	userID, _ := strconv.Atoi(ctx.Param("id"))
+
+	orders, count, err := c.orderUsecase.GetOrdersByUserID(ctx.Request().Context(), page, limit, uint(userID))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get user orders", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get user orders",
+			orders,
+			page,
+			limit,
+			count,
+		),
+	)
+}
@@ -43,16 +43,9 @@ func (c *orderDetailController) GetAllOrderDetails(ctx echo.Context) error {
 	userParam := ctx.QueryParam("user_id")
 	user_id, err := strconv.Atoi(userParam)
 
-	orderDetails, count, err := c.orderDetailUsecase.GetAllOrderDetails(page, limit, user_id)
+	orderDetails, count, err := c.orderDetailUsecase.GetAllOrderDetails(ctx.Request().Context(), page, limit, user_id)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get all orderDetail",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get all orderDetail", err)
 	}
 
 	return ctx.JSON(
@@ -70,17 +63,10 @@ func (c *orderDetailController) GetAllOrderDetails(ctx echo.Context) error {
 
 func (c *orderDetailController) GetOrderDetailByID(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
-	orderDetail, err := c.orderDetailUsecase.GetOrderDetailByID(uint(id))
+	orderDetail, err := c.orderDetailUsecase.GetOrderDetailByID(ctx.Request().Context(), uint(id))
 
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get orderDetail by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get orderDetail by id", err)
 	}
 
 	return ctx.JSON(
@@ -97,21 +83,16 @@ func (c *orderDetailController) GetOrderDetailByID(ctx echo.Context) error {
 func (c *orderDetailController) CreateOrderDetail(ctx echo.Context) error {
 	var orderDetailDTO dtos.OrderDetailInput
 	if err := ctx.Bind(&orderDetailDTO); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
 	}
 
-	orderDetail, err := c.orderDetailUsecase.CreateOrderDetail(&orderDetailDTO)
+	if err := ctx.Validate(&orderDetailDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	orderDetail, err := c.orderDetailUsecase.CreateOrderDetail(ctx.Request().Context(), &orderDetailDTO)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to created a orderDetail",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to created a orderDetail", err)
 	}
 
 	return ctx.JSON(
@@ -128,35 +109,23 @@ func (c *orderDetailController) UpdateOrderDetail(ctx echo.Context) error {
 
 	var orderDetailInput dtos.OrderDetailInput
 	if err := ctx.Bind(&orderDetailInput); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&orderDetailInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
 	}
 
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	orderDetail, err := c.orderDetailUsecase.GetOrderDetailByID(uint(id))
+	orderDetail, err := c.orderDetailUsecase.GetOrderDetailByID(ctx.Request().Context(), uint(id))
 	if orderDetail.OrderDetailID == 0 {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get orderDetail by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get orderDetail by id", err)
 	}
 
-	orderDetailResp, err := c.orderDetailUsecase.UpdateOrderDetail(uint(id), orderDetailInput)
+	orderDetailResp, err := c.orderDetailUsecase.UpdateOrderDetail(ctx.Request().Context(), uint(id), orderDetailInput)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to updated a orderDetail",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to updated a orderDetail", err)
 	}
 
 	return ctx.JSON(
@@ -172,11 +141,9 @@ func (c *orderDetailController) UpdateOrderDetail(ctx echo.Context) error {
 func (c *orderDetailController) DeleteOrderDetail(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	err := c.orderDetailUsecase.DeleteOrderDetail(uint(id))
+	err := c.orderDetailUsecase.DeleteOrderDetail(ctx.Request().Context(), uint(id))
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Failed to delete order detail", err)
 	}
 	return ctx.JSON(
 		http.StatusOK,
@@ -3,8 +3,10 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"synapsis-backend/apperrors"
 	"synapsis-backend/dtos"
 	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
 	"synapsis-backend/usecases"
 
 	"github.com/labstack/echo/v4"
@@ -13,6 +15,7 @@ import (
 type PaymentController interface {
 	GetAllPayments(c echo.Context) error
 	GetPaymentByID(c echo.Context) error
+	GetUserPayments(c echo.Context) error
 	CreatePayment(c echo.Context) error
 	UpdatePayment(c echo.Context) error
 	DeletePayment(c echo.Context) error
@@ -43,16 +46,9 @@ func (c *paymentController) GetAllPayments(ctx echo.Context) error {
 	userParam := ctx.QueryParam("user_id")
 	user_id, err := strconv.Atoi(userParam)
 
-	payments, count, err := c.paymentUsecase.GetAllPayments(page, limit, user_id)
+	payments, count, err := c.paymentUsecase.GetAllPayments(ctx.Request().Context(), page, limit, user_id)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get all payment",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get all payment", err)
 	}
 
 	return ctx.JSON(
@@ -70,17 +66,10 @@ func (c *paymentController) GetAllPayments(ctx echo.Context) error {
 
 func (c *paymentController) GetPaymentByID(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
-	payment, err := c.paymentUsecase.GetPaymentByID(uint(id))
+	payment, err := c.paymentUsecase.GetPaymentByID(ctx.Request().Context(), uint(id))
 
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get payment by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get payment by id", err)
 	}
 
 	return ctx.JSON(
@@ -95,25 +84,50 @@ func (c *paymentController) GetPaymentByID(ctx echo.Context) error {
 }
 
 func (c *paymentController) CreatePayment(ctx echo.Context) error {
-	var paymentDTO dtos.PaymentInput
-	if err := ctx.Bind(&paymentDTO); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
 	}
 
-	payment, err := c.paymentUsecase.CreatePayment(&paymentDTO)
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to created a payment",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	var paymentDTO dtos.PaymentInput
+	if err := ctx.Bind(&paymentDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	// The payment is made for the caller; user_id may be left out of the body.
+	if paymentDTO.UserID != 0 && paymentDTO.UserID != userId {
+		return helpers.NewHTTPError("Failed to created a payment", apperrors.Forbidden("user_id does not match the token"))
+	}
+	paymentDTO.UserID = userId
+
+	if err := ctx.Validate(&paymentDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	payment, err := c.paymentUsecase.CreatePayment(ctx.Request().Context(), &paymentDTO)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to created a payment", err)
+	}
+
 	return ctx.JSON(
 		http.StatusCreated,
 		helpers.NewResponse(
@@ -128,35 +142,23 @@ func (c *paymentController) UpdatePayment(ctx echo.Context) error {
 
 	var paymentInput dtos.PaymentInput
 	if err := ctx.Bind(&paymentInput); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&paymentInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
 	}
 
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	payment, err := c.paymentUsecase.GetPaymentByID(uint(id))
+	payment, err := c.paymentUsecase.GetPaymentByID(ctx.Request().Context(), uint(id))
 	if payment.PaymentID == 0 {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get payment by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get payment by id", err)
 	}
 
-	paymentResp, err := c.paymentUsecase.UpdatePayment(uint(id), paymentInput)
+	paymentResp, err := c.paymentUsecase.UpdatePayment(ctx.Request().Context(), uint(id), paymentInput)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to updated a payment",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to updated a payment", err)
 	}
 
 	return ctx.JSON(
@@ -172,11 +174,9 @@ func (c *paymentController) UpdatePayment(ctx echo.Context) error {
 func (c *paymentController) DeletePayment(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	err := c.paymentUsecase.DeletePayment(uint(id))
+	err := c.paymentUsecase.DeletePayment(ctx.Request().Context(), uint(id))
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Failed to delete payment", err)
 	}
 	return ctx.JSON(
 		http.StatusOK,
@@ -187,3 +187,35 @@ func (c *paymentController) DeletePayment(ctx echo.Context) error {
 		),
 	)
 }
+
+func (c *paymentController) GetUserPayments(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+	userID, _ := strconv.Atoi(ctx.Param("id"))
+
+	payments, count, err := c.paymentUsecase.GetPaymentsByUserID(ctx.Request().Context(), page, limit, uint(userID))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get user payments", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get user payments",
+			payments,
+			page,
+			limit,
+			count,
+		),
+	)
+}
@@ -47,16 +47,9 @@ func (c *productController) GetAllProducts(ctx echo.Context) error {
 		category_id = 0
 	}
 
-	products, count, err := c.productUsecase.GetAllProducts(page, limit, category_id)
+	products, count, err := c.productUsecase.GetAllProducts(ctx.Request().Context(), page, limit, category_id)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get all product",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get all product", err)
 	}
 
 	return ctx.JSON(
@@ -74,17 +67,10 @@ func (c *productController) GetAllProducts(ctx echo.Context) error {
 
 func (c *productController) GetProductByID(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
-	product, err := c.productUsecase.GetProductByID(uint(id))
+	product, err := c.productUsecase.GetProductByID(ctx.Request().Context(), uint(id))
 
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get product by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get product by id", err)
 	}
 
 	return ctx.JSON(
@@ -101,21 +87,16 @@ func (c *productController) GetProductByID(ctx echo.Context) error {
 func (c *productController) CreateProduct(ctx echo.Context) error {
 	var productDTO dtos.ProductInput
 	if err := ctx.Bind(&productDTO); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
 	}
 
-	product, err := c.productUsecase.CreateProduct(&productDTO)
+	if err := ctx.Validate(&productDTO); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	product, err := c.productUsecase.CreateProduct(ctx.Request().Context(), &productDTO)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to created a product",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to created a product", err)
 	}
 
 	return ctx.JSON(
@@ -132,35 +113,23 @@ func (c *productController) UpdateProduct(ctx echo.Context) error {
 
 	var productInput dtos.ProductInput
 	if err := ctx.Bind(&productInput); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&productInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
 	}
 
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	product, err := c.productUsecase.GetProductByID(uint(id))
+	product, err := c.productUsecase.GetProductByID(ctx.Request().Context(), uint(id))
 	if product.ProductID == 0 {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get product by id",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to get product by id", err)
 	}
 
-	productResp, err := c.productUsecase.UpdateProduct(uint(id), productInput)
+	productResp, err := c.productUsecase.UpdateProduct(ctx.Request().Context(), uint(id), productInput)
 	if err != nil {
-		return ctx.JSON(
-			http.StatusBadRequest,
-			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to updated a product",
-				helpers.GetErrorData(err),
-			),
-		)
+		return helpers.NewHTTPError("Failed to updated a product", err)
 	}
 
 	return ctx.JSON(
@@ -176,11 +145,9 @@ func (c *productController) UpdateProduct(ctx echo.Context) error {
 func (c *productController) DeleteProduct(ctx echo.Context) error {
 	id, _ := strconv.Atoi(ctx.Param("id"))
 
-	err := c.productUsecase.DeleteProduct(uint(id))
+	err := c.productUsecase.DeleteProduct(ctx.Request().Context(), uint(id))
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, dtos.ErrorDTO{
-			Message: err.Error(),
-		})
+		return helpers.NewHTTPError("Failed to delete product", err)
 	}
 	return ctx.JSON(
 		http.StatusOK,
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewController interface {
+	GetProductReviews(c echo.Context) error
+	CreateReview(c echo.Context) error
+	UpdateReview(c echo.Context) error
+	GetAllReviews(c echo.Context) error
+	HideReview(c echo.Context) error
+	UnhideReview(c echo.Context) error
+}
+
+type reviewController struct {
+	reviewUsecase usecases.ReviewUsecase
+}
+
+func NewReviewController(reviewUsecase usecases.ReviewUsecase) ReviewController {
+	return &reviewController{reviewUsecase}
+}
+
+func (c *reviewController) GetProductReviews(ctx echo.Context) error {
+	productId, _ := strconv.Atoi(ctx.Param("id"))
+
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+
+	reviews, count, err := c.reviewUsecase.GetProductReviews(ctx.Request().Context(), page, limit, uint(productId))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get product reviews", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get product reviews",
+			reviews,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *reviewController) CreateReview(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var reviewInput dtos.ReviewInput
+	if err := ctx.Bind(&reviewInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&reviewInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	productId, _ := strconv.Atoi(ctx.Param("id"))
+
+	review, err := c.reviewUsecase.CreateReview(ctx.Request().Context(), userId, uint(productId), reviewInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to created a review", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully to created a review",
+			review,
+		),
+	)
+}
+
+func (c *reviewController) UpdateReview(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var reviewInput dtos.ReviewInput
+	if err := ctx.Bind(&reviewInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&reviewInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	productId, _ := strconv.Atoi(ctx.Param("id"))
+
+	review, err := c.reviewUsecase.UpdateReview(ctx.Request().Context(), userId, uint(productId), reviewInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to updated a review", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully updated review",
+			review,
+		),
+	)
+}
+
+func (c *reviewController) GetAllReviews(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+
+	productIdParam := ctx.QueryParam("product_id")
+	productId, err := strconv.Atoi(productIdParam)
+	if err != nil {
+		productId = 0
+	}
+	status := ctx.QueryParam("status")
+
+	reviews, count, err := c.reviewUsecase.GetAllReviews(ctx.Request().Context(), page, limit, uint(productId), status)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get all review", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get all reviews",
+			reviews,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *reviewController) HideReview(ctx echo.Context) error {
+	var reviewInput dtos.ReviewHideInput
+	if err := ctx.Bind(&reviewInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&reviewInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	review, err := c.reviewUsecase.HideReview(ctx.Request().Context(), uint(id), reviewInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to hide review", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully hid review",
+			review,
+		),
+	)
+}
+
+func (c *reviewController) UnhideReview(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	review, err := c.reviewUsecase.UnhideReview(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to unhide review", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully unhid review",
+			review,
+		),
+	)
+}
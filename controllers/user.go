@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"synapsis-backend/apperrors"
 	"synapsis-backend/dtos"
 	"synapsis-backend/helpers"
 	"synapsis-backend/middlewares"
@@ -23,75 +26,155 @@ func (c *UserController) UserLogin(ctx echo.Context) error {
 
 	err := ctx.Bind(&userInput)
 	if err != nil {
+		return helpers.NewHTTPError("Failed to login", err)
+	}
+
+	if err := ctx.Validate(&userInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.UserLogin(ctx.Request().Context(), userInput, ctx.RealIP())
+	if err != nil {
+		return helpers.NewHTTPError("Failed to login", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully logged in",
+			user,
+		),
+	)
+}
+
+func (c *UserController) UserRegister(ctx echo.Context) error {
+	var userInput dtos.UserRegisterInput
+	err := ctx.Bind(&userInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to register", err)
+	}
+
+	if err := ctx.Validate(&userInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.UserRegister(ctx.Request().Context(), userInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to register", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully registered",
+			user,
+		),
+	)
+}
+
+func (c *UserController) UserUpdateInformation(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to login",
-				helpers.GetErrorData(err),
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
 			),
 		)
 	}
 
-	user, err := c.userUsecase.UserLogin(userInput)
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to login",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	var userInput dtos.UserUpdateInformationInput
+	err = ctx.Bind(&userInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to update information", err)
+	}
+
+	if err := ctx.Validate(&userInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.UserUpdateInformation(ctx.Request().Context(), userId, userInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to update information", err)
+	}
+
 	return ctx.JSON(
 		http.StatusOK,
 		helpers.NewResponse(
 			http.StatusOK,
-			"Successfully logged in",
+			"Successfully updated information",
 			user,
 		),
 	)
 }
 
-func (c *UserController) UserRegister(ctx echo.Context) error {
-	var userInput dtos.UserRegisterInput
-	err := ctx.Bind(&userInput)
-	if err != nil {
+func (c *UserController) UserUpdatePassword(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to register",
-				helpers.GetErrorData(err),
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
 			),
 		)
 	}
 
-	user, err := c.userUsecase.UserRegister(userInput)
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to register",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	var userInput dtos.UserUpdatePasswordInput
+	err = ctx.Bind(&userInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to update password", err)
+	}
+
+	if err := ctx.Validate(&userInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.UserUpdatePassword(ctx.Request().Context(), userId, userInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to update password", err)
+	}
+
 	return ctx.JSON(
-		http.StatusCreated,
+		http.StatusOK,
 		helpers.NewResponse(
-			http.StatusCreated,
-			"Successfully registered",
+			http.StatusOK,
+			"Successfully updated password",
 			user,
 		),
 	)
 }
 
-func (c *UserController) UserUpdateInformation(ctx echo.Context) error {
+func (c *UserController) UserUpdateProfile(ctx echo.Context) error {
 	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
 	if tokenString == "" {
 		return ctx.JSON(
@@ -116,42 +199,97 @@ func (c *UserController) UserUpdateInformation(ctx echo.Context) error {
 		)
 	}
 
-	var userInput dtos.UserUpdateInformationInput
+	var userInput dtos.UserUpdateProfileInput
 	err = ctx.Bind(&userInput)
 	if err != nil {
+		return helpers.NewHTTPError("Failed to update profile", err)
+	}
+
+	if err := ctx.Validate(&userInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.UserUpdateProfile(ctx.Request().Context(), userId, userInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to update profile", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully updated profile",
+			user,
+		),
+	)
+}
+
+func (c *UserController) UserCredential(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to update information",
-				helpers.GetErrorData(err),
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
 			),
 		)
 	}
 
-	user, err := c.userUsecase.UserUpdateInformation(userId, userInput)
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to update information",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	user, err := c.userUsecase.UserCredential(ctx.Request().Context(), userId)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get user credentials", err)
+	}
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully get user credentials",
+			user,
+		),
+	)
+}
+
+func (c *UserController) VerifyEmail(ctx echo.Context) error {
+	var verifyInput dtos.VerifyEmailInput
+	err := ctx.Bind(&verifyInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to verify email", err)
+	}
+
+	if err := ctx.Validate(&verifyInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.VerifyEmail(ctx.Request().Context(), verifyInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to verify email", err)
+	}
+
 	return ctx.JSON(
 		http.StatusOK,
 		helpers.NewResponse(
 			http.StatusOK,
-			"Successfully updated information",
+			"Successfully verified email",
 			user,
 		),
 	)
 }
 
-func (c *UserController) UserUpdatePassword(ctx echo.Context) error {
+func (c *UserController) ResendVerificationEmail(ctx echo.Context) error {
 	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
 	if tokenString == "" {
 		return ctx.JSON(
@@ -176,42 +314,188 @@ func (c *UserController) UserUpdatePassword(ctx echo.Context) error {
 		)
 	}
 
-	var userInput dtos.UserUpdatePasswordInput
-	err = ctx.Bind(&userInput)
+	err = c.userUsecase.ResendVerificationEmail(ctx.Request().Context(), userId)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to send verification email", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully sent verification email",
+			nil,
+		),
+	)
+}
+
+func (c *UserController) ForgotPassword(ctx echo.Context) error {
+	var forgotInput dtos.ForgotPasswordInput
+	err := ctx.Bind(&forgotInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to request password reset", err)
+	}
+
+	if err := ctx.Validate(&forgotInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	err = c.userUsecase.ForgotPassword(ctx.Request().Context(), forgotInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to request password reset", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"If the email is registered, a password reset link has been sent",
+			nil,
+		),
+	)
+}
+
+func (c *UserController) ResetPassword(ctx echo.Context) error {
+	var resetInput dtos.ResetPasswordInput
+	err := ctx.Bind(&resetInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to reset password", err)
+	}
+
+	if err := ctx.Validate(&resetInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	err = c.userUsecase.ResetPassword(ctx.Request().Context(), resetInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to reset password", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully reset password",
+			nil,
+		),
+	)
+}
+
+func (c *UserController) GetLockoutEvents(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+	userID, _ := strconv.Atoi(ctx.QueryParam("user_id"))
+
+	events, count, err := c.userUsecase.GetLockoutEvents(ctx.Request().Context(), page, limit, uint(userID))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get lockout events", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get lockout events",
+			events,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *UserController) UnlockUser(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	adminId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to update password",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
-	user, err := c.userUsecase.UserUpdatePassword(userId, userInput)
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	err = c.userUsecase.UnlockUser(ctx.Request().Context(), adminId, uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to unlock user", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully unlocked user",
+			nil,
+		),
+	)
+}
+
+func (c *UserController) SetupTwoFactor(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to update password",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	setup, err := c.userUsecase.SetupTwoFactor(ctx.Request().Context(), userId)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to set up two-factor authentication", err)
+	}
+
 	return ctx.JSON(
 		http.StatusOK,
 		helpers.NewResponse(
 			http.StatusOK,
-			"Successfully updated password",
-			user,
+			"Successfully set up two-factor authentication",
+			setup,
 		),
 	)
 }
 
-func (c *UserController) UserUpdateProfile(ctx echo.Context) error {
+func (c *UserController) EnableTwoFactor(ctx echo.Context) error {
 	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
 	if tokenString == "" {
 		return ctx.JSON(
@@ -236,42 +520,272 @@ func (c *UserController) UserUpdateProfile(ctx echo.Context) error {
 		)
 	}
 
-	var userInput dtos.UserUpdateProfileInput
-	err = ctx.Bind(&userInput)
+	var codeInput dtos.TwoFactorCodeInput
+	err = ctx.Bind(&codeInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to enable two-factor authentication", err)
+	}
+
+	if err := ctx.Validate(&codeInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	codes, err := c.userUsecase.EnableTwoFactor(ctx.Request().Context(), userId, codeInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to enable two-factor authentication", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully enabled two-factor authentication",
+			codes,
+		),
+	)
+}
+
+func (c *UserController) DisableTwoFactor(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to update profile",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
-	user, err := c.userUsecase.UserUpdateProfile(userId, userInput)
+	var disableInput dtos.TwoFactorDisableInput
+	err = ctx.Bind(&disableInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to disable two-factor authentication", err)
+	}
+
+	if err := ctx.Validate(&disableInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	err = c.userUsecase.DisableTwoFactor(ctx.Request().Context(), userId, disableInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to disable two-factor authentication", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully disabled two-factor authentication",
+			nil,
+		),
+	)
+}
+
+func (c *UserController) RegenerateRecoveryCodes(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to update profile",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
 
+	var codeInput dtos.TwoFactorCodeInput
+	err = ctx.Bind(&codeInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to regenerate recovery codes", err)
+	}
+
+	if err := ctx.Validate(&codeInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	codes, err := c.userUsecase.RegenerateRecoveryCodes(ctx.Request().Context(), userId, codeInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to regenerate recovery codes", err)
+	}
+
 	return ctx.JSON(
 		http.StatusOK,
 		helpers.NewResponse(
 			http.StatusOK,
-			"Successfully updated profile",
+			"Successfully regenerated recovery codes",
+			codes,
+		),
+	)
+}
+
+func (c *UserController) VerifyTwoFactorLogin(ctx echo.Context) error {
+	var loginInput dtos.TwoFactorLoginInput
+	err := ctx.Bind(&loginInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to login", err)
+	}
+
+	if err := ctx.Validate(&loginInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.VerifyTwoFactorLogin(ctx.Request().Context(), loginInput, ctx.RealIP())
+	if err != nil {
+		return helpers.NewHTTPError("Failed to login", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully logged in",
 			user,
 		),
 	)
 }
 
-func (c *UserController) UserCredential(ctx echo.Context) error {
+// oidcStateCookie keeps the OIDC state in the browser that started the
+// login, the callback only accepts the state it holds.
+const oidcStateCookie = "oidc_state"
+
+func (c *UserController) OIDCLogin(ctx echo.Context) error {
+	auth, err := c.userUsecase.GetOIDCAuthURL(ctx.Request().Context(), ctx.Param("provider"))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to login", err)
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    auth.State,
+		Path:     "/api/v1/auth",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   ctx.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return ctx.Redirect(http.StatusFound, auth.AuthURL)
+}
+
+func (c *UserController) OIDCCallback(ctx echo.Context) error {
+	var callbackInput dtos.OIDCCallbackInput
+	err := ctx.Bind(&callbackInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to login", err)
+	}
+
+	cookieState := ""
+	if cookie, err := ctx.Cookie(oidcStateCookie); err == nil {
+		cookieState = cookie.Value
+	}
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/api/v1/auth",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	user, err := c.userUsecase.OIDCCallback(ctx.Request().Context(), ctx.Param("provider"), callbackInput, cookieState, ctx.RealIP())
+	if err != nil {
+		return helpers.NewHTTPError("Failed to login", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully logged in",
+			user,
+		),
+	)
+}
+
+func (c *UserController) ExportUserData(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	export, err := c.userUsecase.ExportUserData(ctx.Request().Context(), userId)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to export user data", err)
+	}
+
+	if ctx.QueryParam("format") == "zip" {
+		archive, err := helpers.ZipJSON(map[string]interface{}{
+			"profile.json":  export.Profile,
+			"carts.json":    export.Carts,
+			"orders.json":   export.Orders,
+			"payments.json": export.Payments,
+			"reviews.json":  export.Reviews,
+		})
+		if err != nil {
+			return helpers.NewHTTPError("Failed to export user data", apperrors.Internal(err))
+		}
+		ctx.Response().Header().Set(
+			echo.HeaderContentDisposition,
+			fmt.Sprintf("attachment; filename=user-%d-export-%s.zip", userId, export.ExportedAt.Format("20060102")),
+		)
+		return ctx.Blob(http.StatusOK, "application/zip", archive)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully exported user data",
+			export,
+		),
+	)
+}
+
+func (c *UserController) DeleteUser(ctx echo.Context) error {
 	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
 	if tokenString == "" {
 		return ctx.JSON(
@@ -296,23 +810,171 @@ func (c *UserController) UserCredential(ctx echo.Context) error {
 		)
 	}
 
-	user, err := c.userUsecase.UserCredential(userId)
+	var deleteInput dtos.UserDeleteInput
+	err = ctx.Bind(&deleteInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to delete account", err)
+	}
+
+	if err := ctx.Validate(&deleteInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	err = c.userUsecase.DeleteUser(ctx.Request().Context(), userId, deleteInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to delete account", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully deleted account",
+			nil,
+		),
+	)
+}
+
+func (c *UserController) GetAllUsers(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+	filter := dtos.UserFilterInput{
+		Search:         ctx.QueryParam("search"),
+		Role:           ctx.QueryParam("role"),
+		Status:         ctx.QueryParam("status"),
+		RegisteredFrom: ctx.QueryParam("registered_from"),
+		RegisteredTo:   ctx.QueryParam("registered_to"),
+	}
+
+	users, count, err := c.userUsecase.GetAllUsers(ctx.Request().Context(), page, limit, filter)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get users", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get users",
+			users,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *UserController) GetUserByID(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	user, err := c.userUsecase.GetUserByID(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get user", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully get user",
+			user,
+		),
+	)
+}
+
+func (c *UserController) SuspendUser(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	adminId, err := middlewares.GetUserIdFromToken(tokenString)
 	if err != nil {
 		return ctx.JSON(
-			http.StatusBadRequest,
+			http.StatusUnauthorized,
 			helpers.NewErrorResponse(
-				http.StatusBadRequest,
-				"Failed to get user credentials",
+				http.StatusUnauthorized,
+				"No token provided",
 				helpers.GetErrorData(err),
 			),
 		)
 	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	var suspendInput dtos.UserSuspendInput
+	err = ctx.Bind(&suspendInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to suspend user", err)
+	}
+
+	if err := ctx.Validate(&suspendInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	user, err := c.userUsecase.SuspendUser(ctx.Request().Context(), adminId, uint(id), suspendInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to suspend user", err)
+	}
+
 	return ctx.JSON(
 		http.StatusOK,
 		helpers.NewResponse(
 			http.StatusOK,
-			"Successfully get user credentials",
+			"Successfully suspended user",
+			user,
+		),
+	)
+}
+
+func (c *UserController) ReactivateUser(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	user, err := c.userUsecase.ReactivateUser(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to reactivate user", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully reactivated user",
 			user,
 		),
 	)
 }
+
+func (c *UserController) ForcePasswordReset(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	err := c.userUsecase.ForcePasswordReset(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to force password reset", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully forced password reset",
+			nil,
+		),
+	)
+}
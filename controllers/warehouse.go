@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WarehouseController interface {
+	GetAllWarehouses(c echo.Context) error
+	GetWarehouseByID(c echo.Context) error
+	CreateWarehouse(c echo.Context) error
+	UpdateWarehouse(c echo.Context) error
+	DeleteWarehouse(c echo.Context) error
+	GetWarehouseStocks(c echo.Context) error
+	GetAllStockTransfers(c echo.Context) error
+	TransferStock(c echo.Context) error
+}
+
+type warehouseController struct {
+	warehouseUsecase usecases.WarehouseUsecase
+}
+
+func NewWarehouseController(warehouseUsecase usecases.WarehouseUsecase) WarehouseController {
+	return &warehouseController{warehouseUsecase}
+}
+
+func (c *warehouseController) GetAllWarehouses(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+
+	warehouses, count, err := c.warehouseUsecase.GetAllWarehouses(ctx.Request().Context(), page, limit)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get all warehouse", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get all warehouses",
+			warehouses,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *warehouseController) GetWarehouseByID(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	warehouse, err := c.warehouseUsecase.GetWarehouseByID(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get warehouse by id", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully get warehouse by id",
+			warehouse,
+		),
+	)
+}
+
+func (c *warehouseController) CreateWarehouse(ctx echo.Context) error {
+	var warehouseInput dtos.WarehouseInput
+	if err := ctx.Bind(&warehouseInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&warehouseInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	warehouse, err := c.warehouseUsecase.CreateWarehouse(ctx.Request().Context(), &warehouseInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to create warehouse", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully created warehouse",
+			warehouse,
+		),
+	)
+}
+
+func (c *warehouseController) UpdateWarehouse(ctx echo.Context) error {
+	var warehouseInput dtos.WarehouseInput
+	if err := ctx.Bind(&warehouseInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&warehouseInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	warehouse, err := c.warehouseUsecase.UpdateWarehouse(ctx.Request().Context(), uint(id), warehouseInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to update warehouse", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully updated warehouse",
+			warehouse,
+		),
+	)
+}
+
+func (c *warehouseController) DeleteWarehouse(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	err := c.warehouseUsecase.DeleteWarehouse(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to delete warehouse", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully deleted warehouse",
+			nil,
+		),
+	)
+}
+
+func (c *warehouseController) GetWarehouseStocks(ctx echo.Context) error {
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	stocks, err := c.warehouseUsecase.GetWarehouseStocks(ctx.Request().Context(), uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get warehouse stocks", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully get warehouse stocks",
+			stocks,
+		),
+	)
+}
+
+func (c *warehouseController) GetAllStockTransfers(ctx echo.Context) error {
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+	productID, _ := strconv.Atoi(ctx.QueryParam("product_id"))
+
+	transfers, count, err := c.warehouseUsecase.GetAllStockTransfers(ctx.Request().Context(), page, limit, uint(productID))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get stock transfers", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get stock transfers",
+			transfers,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *warehouseController) TransferStock(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var transferInput dtos.StockTransferInput
+	if err := ctx.Bind(&transferInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&transferInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	transfer, err := c.warehouseUsecase.TransferStock(ctx.Request().Context(), userId, transferInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to transfer stock", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully transferred stock",
+			transfer,
+		),
+	)
+}
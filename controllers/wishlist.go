@@ -0,0 +1,351 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/usecases"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WishlistController interface {
+	GetAllWishlists(c echo.Context) error
+	GetWishlistByID(c echo.Context) error
+	CreateWishlist(c echo.Context) error
+	UpdateWishlist(c echo.Context) error
+	DeleteWishlist(c echo.Context) error
+	MoveToCart(c echo.Context) error
+	SaveForLater(c echo.Context) error
+}
+
+type wishlistController struct {
+	wishlistUsecase usecases.WishlistUsecase
+}
+
+func NewWishlistController(wishlistUsecase usecases.WishlistUsecase) WishlistController {
+	return &wishlistController{wishlistUsecase}
+}
+
+func (c *wishlistController) GetAllWishlists(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	pageParam := ctx.QueryParam("page")
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		page = 1
+	}
+
+	limitParam := ctx.QueryParam("limit")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		limit = 10
+	}
+
+	wishlists, count, err := c.wishlistUsecase.GetAllWishlists(ctx.Request().Context(), page, limit, userId)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get all wishlist", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewPaginationResponse(
+			http.StatusOK,
+			"Successfully get all wishlists",
+			wishlists,
+			page,
+			limit,
+			count,
+		),
+	)
+}
+
+func (c *wishlistController) GetWishlistByID(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+	wishlist, err := c.wishlistUsecase.GetWishlistByID(ctx.Request().Context(), userId, uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to get wishlist by id", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully to get wishlist by id",
+			wishlist,
+		),
+	)
+}
+
+func (c *wishlistController) CreateWishlist(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var wishlistInput dtos.WishlistInput
+	if err := ctx.Bind(&wishlistInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&wishlistInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	wishlist, err := c.wishlistUsecase.CreateWishlist(ctx.Request().Context(), userId, wishlistInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to created a wishlist", err)
+	}
+
+	return ctx.JSON(
+		http.StatusCreated,
+		helpers.NewResponse(
+			http.StatusCreated,
+			"Successfully to created a wishlist",
+			wishlist,
+		),
+	)
+}
+
+func (c *wishlistController) UpdateWishlist(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	var wishlistInput dtos.WishlistUpdateInput
+	if err := ctx.Bind(&wishlistInput); err != nil {
+		return helpers.NewHTTPError("Invalid request body", err)
+	}
+
+	if err := ctx.Validate(&wishlistInput); err != nil {
+		return helpers.NewHTTPError("Invalid request data", err)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	wishlist, err := c.wishlistUsecase.UpdateWishlist(ctx.Request().Context(), userId, uint(id), wishlistInput)
+	if err != nil {
+		return helpers.NewHTTPError("Failed to updated a wishlist", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully updated wishlist",
+			wishlist,
+		),
+	)
+}
+
+func (c *wishlistController) DeleteWishlist(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	err = c.wishlistUsecase.DeleteWishlist(ctx.Request().Context(), userId, uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to delete wishlist", err)
+	}
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully deleted wishlist",
+			nil,
+		),
+	)
+}
+
+func (c *wishlistController) MoveToCart(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	cart, err := c.wishlistUsecase.MoveToCart(ctx.Request().Context(), userId, uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to move wishlist to cart", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully moved wishlist to cart",
+			cart,
+		),
+	)
+}
+
+func (c *wishlistController) SaveForLater(ctx echo.Context) error {
+	tokenString := middlewares.GetTokenFromHeader(ctx.Request())
+	if tokenString == "" {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(nil),
+			),
+		)
+	}
+
+	userId, err := middlewares.GetUserIdFromToken(tokenString)
+	if err != nil {
+		return ctx.JSON(
+			http.StatusUnauthorized,
+			helpers.NewErrorResponse(
+				http.StatusUnauthorized,
+				"No token provided",
+				helpers.GetErrorData(err),
+			),
+		)
+	}
+
+	id, _ := strconv.Atoi(ctx.Param("id"))
+
+	wishlist, err := c.wishlistUsecase.SaveForLater(ctx.Request().Context(), userId, uint(id))
+	if err != nil {
+		return helpers.NewHTTPError("Failed to save cart for later", err)
+	}
+
+	return ctx.JSON(
+		http.StatusOK,
+		helpers.NewResponse(
+			http.StatusOK,
+			"Successfully saved cart for later",
+			wishlist,
+		),
+	)
+}
@@ -0,0 +1,32 @@
+package dtos
+
+import "time"
+
+type UserFilterInput struct {
+	Search string `query:"search" example:"daniel"`
+	Role   string `query:"role" example:"user"`
+	// Status is active or suspended.
+	Status         string `query:"status" example:"active"`
+	RegisteredFrom string `query:"registered_from" example:"2023-05-01"`
+	RegisteredTo   string `query:"registered_to" example:"2023-05-31"`
+}
+
+type UserSuspendInput struct {
+	Reason string `form:"reason" json:"reason" validate:"required,max=255" example:"Chargeback fraud"`
+}
+
+type AdminUserResponse struct {
+	ID               uint       `json:"id" example:"1"`
+	FullName         string     `json:"full_name" example:"Daniel R Capah"`
+	Email            string     `json:"email" example:"me@gmail.com"`
+	PhoneNumber      string     `json:"phone_number" example:"0852-9614-3297"`
+	Role             string     `json:"role" example:"user"`
+	EmailVerified    bool       `json:"email_verified" example:"true"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" example:"false"`
+	LockedUntil      *time.Time `json:"locked_until" example:"2023-05-17T15:22:16.504+07:00"`
+	SuspendedAt      *time.Time `json:"suspended_at" example:"2023-05-17T15:07:16.504+07:00"`
+	SuspendedReason  string     `json:"suspended_reason" example:"Chargeback fraud"`
+	SuspendedBy      *uint      `json:"suspended_by" example:"1"`
+	CreatedAt        time.Time  `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+	UpdatedAt        time.Time  `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
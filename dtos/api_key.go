@@ -0,0 +1,23 @@
+package dtos
+
+import "time"
+
+type APIKeyInput struct {
+	Name      string     `json:"name" validate:"required,max=100" example:"ERP integration"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required" example:"products:read,orders:read"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2024-05-17T00:00:00+07:00"`
+}
+
+type APIKeyResponse struct {
+	APIKeyID   uint       `json:"api_key_id" example:"1"`
+	Name       string     `json:"name" example:"ERP integration"`
+	Prefix     string     `json:"prefix" example:"sk_3f9a1c"`
+	Scopes     []string   `json:"scopes" example:"products:read,orders:read"`
+	ExpiresAt  *time.Time `json:"expires_at" example:"2024-05-17T00:00:00+07:00"`
+	LastUsedAt *time.Time `json:"last_used_at" example:"2023-05-17T15:07:16.504+07:00"`
+	RevokedAt  *time.Time `json:"revoked_at" example:"2023-05-17T15:07:16.504+07:00"`
+	CreatedBy  uint       `json:"created_by" example:"1"`
+	CreatedAt  time.Time  `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+	// Key is only returned when the key is created.
+	Key *string `json:"key" example:"sk_3f9a1c..."`
+}
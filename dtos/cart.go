@@ -3,10 +3,18 @@ package dtos
 import "time"
 
 type CartInput struct {
-	UserID    uint `json:"user_id" example:"1"`
-	ProductID uint `json:"product_id" example:"1"`
-	Price     int  `json:"price" example:"100000"`
-	Quantity  int  `json:"quantity" example:"2"`
+	UserID    uint `json:"user_id" validate:"required" example:"1"`
+	ProductID uint `json:"product_id" validate:"required" example:"1"`
+	Quantity  int  `json:"quantity" validate:"gt=0" example:"2"`
+}
+
+type CartItemInput struct {
+	ProductID uint `json:"product_id" validate:"required" example:"1"`
+	Quantity  int  `json:"quantity" validate:"gt=0" example:"2"`
+}
+
+type CartBatchInput struct {
+	Items []CartItemInput `json:"items" validate:"dive"`
 }
 
 type CartResponse struct {
@@ -18,3 +26,29 @@ type CartResponse struct {
 	CreatedAt time.Time `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
 	UpdatedAt time.Time `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
 }
+
+type CartSummaryItemResponse struct {
+	CartID       uint   `json:"cart_id" example:"1"`
+	ProductID    uint   `json:"product_id" example:"1"`
+	ProductName  string `json:"product_name" example:"Erigo"`
+	Quantity     int    `json:"quantity" example:"2"`
+	AddedPrice   int    `json:"added_price" example:"100000"`
+	CurrentPrice int    `json:"current_price" example:"100000"`
+	PriceChanged bool   `json:"price_changed" example:"false"`
+	Stock        int    `json:"stock" example:"100"`
+	StockStatus  string `json:"stock_status" example:"in_stock"`
+	SubTotal     int    `json:"sub_total" example:"200000"`
+	Discount     int    `json:"discount" example:"0"`
+	Total        int    `json:"total" example:"200000"`
+}
+
+type CartSummaryResponse struct {
+	UserID       uint                      `json:"user_id" example:"1"`
+	Items        []CartSummaryItemResponse `json:"items"`
+	TotalItems   int                       `json:"total_items" example:"2"`
+	SubTotal     int                       `json:"sub_total" example:"200000"`
+	Discount     int                       `json:"discount" example:"0"`
+	EstimatedTax int                       `json:"estimated_tax" example:"22000"`
+	GrandTotal   int                       `json:"grand_total" example:"222000"`
+	PriceChanged bool                      `json:"price_changed" example:"false"`
+}
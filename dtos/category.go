@@ -3,7 +3,7 @@ package dtos
 import "time"
 
 type CategoryInput struct {
-	Category string `json:"category" form:"category" example:"pakaian"`
+	Category string `json:"category" form:"category" validate:"required,max=100" example:"pakaian"`
 }
 
 type CategoryResponse struct {
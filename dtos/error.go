@@ -1,5 +0,0 @@
-package dtos
-
-type ErrorDTO struct {
-	Message string `json:"message"`
-}
@@ -0,0 +1,26 @@
+package dtos
+
+import "time"
+
+type HealthResponse struct {
+	// Status is "ok" when every check passed, "unavailable" otherwise and
+	// "shutting_down" once the server received SIGTERM.
+	Status string                 `json:"status" example:"ok"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+type HealthCheck struct {
+	Status    string  `json:"status" example:"ok"`
+	Error     string  `json:"error,omitempty" example:""`
+	LatencyMs float64 `json:"latency_ms,omitempty" example:"1.2"`
+	// Pending lists the migrations not applied yet.
+	Pending []string               `json:"pending,omitempty"`
+	Workers []WorkerStatusResponse `json:"workers,omitempty"`
+}
+
+type WorkerStatusResponse struct {
+	Name      string     `json:"name" example:"low_stock_monitor"`
+	Running   bool       `json:"running" example:"true"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty" example:"2023-05-17T15:07:16.504+07:00"`
+	LastError string     `json:"last_error,omitempty" example:""`
+}
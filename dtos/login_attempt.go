@@ -0,0 +1,15 @@
+package dtos
+
+import "time"
+
+type LockoutEventResponse struct {
+	LockoutEventID uint       `json:"lockout_event_id" example:"1"`
+	UserID         uint       `json:"user_id" example:"1"`
+	Email          string     `json:"email" example:"daniel@gmail.com"`
+	IP             string     `json:"ip" example:"203.0.113.7"`
+	FailedAttempts int        `json:"failed_attempts" example:"5"`
+	LockedUntil    time.Time  `json:"locked_until" example:"2023-05-17T15:22:16.504+07:00"`
+	UnlockedAt     *time.Time `json:"unlocked_at" example:"2023-05-17T15:10:16.504+07:00"`
+	UnlockedBy     *uint      `json:"unlocked_by" example:"1"`
+	CreatedAt      time.Time  `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
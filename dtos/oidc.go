@@ -0,0 +1,12 @@
+package dtos
+
+type OIDCAuthResponse struct {
+	AuthURL string `json:"auth_url" example:"https://accounts.example.com/authorize?client_id=..."`
+	State   string `json:"state" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+type OIDCCallbackInput struct {
+	Code  string `query:"code" json:"code" example:"SplxlOBeZQQYbYS6WxSbIA"`
+	State string `query:"state" json:"state" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Error string `query:"error" json:"error" example:""`
+}
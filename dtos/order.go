@@ -3,12 +3,15 @@ package dtos
 import "time"
 
 type OrderInput struct {
-	UserID     uint `json:"user_id" example:"1"`
-	TotalPrice int  `json:"total_price" example:"100000"`
+	UserID     uint `json:"user_id" validate:"required" example:"1"`
+	TotalPrice int  `json:"total_price" validate:"gte=0" example:"100000"`
 }
 
 type OrderInputCheckout struct {
-	UserID uint `json:"user_id" example:"1"`
+	UserID            uint     `json:"user_id" validate:"required" example:"1"`
+	ShippingAddress   string   `json:"shipping_address" validate:"omitempty,max=255" example:"Jl. Merdeka No. 1, Jakarta"`
+	ShippingLatitude  *float64 `json:"shipping_latitude" validate:"omitempty,latitude" example:"-6.175392"`
+	ShippingLongitude *float64 `json:"shipping_longitude" validate:"omitempty,longitude" example:"106.827153"`
 }
 
 type OrderResponse struct {
@@ -21,11 +24,12 @@ type OrderResponse struct {
 }
 
 type OrderResponseCheckout struct {
-	OrderID     uint                  `json:"order_id" example:"1"`
-	UserID      uint                  `json:"user_id" example:"1"`
-	TotalPrice  int                   `json:"total_price" example:"100000"`
-	Status      string                `json:"status" example:"unpaid"`
-	OrderDetail []OrderDetailResponse `json:"order_detail"`
-	CreatedAt   time.Time             `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
-	UpdatedAt   time.Time             `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
+	OrderID         uint                  `json:"order_id" example:"1"`
+	UserID          uint                  `json:"user_id" example:"1"`
+	TotalPrice      int                   `json:"total_price" example:"100000"`
+	Status          string                `json:"status" example:"unpaid"`
+	ShippingAddress string                `json:"shipping_address" example:"Jl. Merdeka No. 1, Jakarta"`
+	OrderDetail     []OrderDetailResponse `json:"order_detail"`
+	CreatedAt       time.Time             `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+	UpdatedAt       time.Time             `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
 }
@@ -3,17 +3,18 @@ package dtos
 import "time"
 
 type OrderDetailInput struct {
-	ProductID uint `json:"product_id" example:"1"`
-	OrderID   uint `json:"order_id" example:"1"`
-	Quantity  int  `json:"quantity" example:"2"`
-	SubTotal  int  `json:"sub_total" example:"200000"`
-	Discount  int  `json:"discount" example:"0"`
+	ProductID uint `json:"product_id" validate:"required" example:"1"`
+	OrderID   uint `json:"order_id" validate:"required" example:"1"`
+	Quantity  int  `json:"quantity" validate:"gt=0" example:"2"`
+	SubTotal  int  `json:"sub_total" validate:"gte=0" example:"200000"`
+	Discount  int  `json:"discount" validate:"gte=0" example:"0"`
 }
 
 type OrderDetailResponse struct {
 	OrderDetailID uint      `json:"order_detail_id" example:"1"`
 	ProductID     uint      `json:"product_id" example:"1"`
 	OrderID       uint      `json:"order_id" example:"1"`
+	WarehouseID   *uint     `json:"warehouse_id" example:"1"`
 	Quantity      int       `json:"quantity" example:"2"`
 	SubTotal      int       `json:"sub_total" example:"200000"`
 	Discount      int       `json:"discount" example:"0"`
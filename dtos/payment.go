@@ -3,10 +3,10 @@ package dtos
 import "time"
 
 type PaymentInput struct {
-	OrderID     uint   `json:"order_id" example:"1"`
-	UserID      uint   `json:"user_id" example:"1"`
-	PaymentType string `json:"payment_type" example:"100000"`
-	Amount      int    `json:"amount" example:"100000"`
+	OrderID     uint   `json:"order_id" validate:"required" example:"1"`
+	UserID      uint   `json:"user_id" validate:"required" example:"1"`
+	PaymentType string `json:"payment_type" validate:"required,max=50" example:"100000"`
+	Amount      int    `json:"amount" validate:"gt=0" example:"100000"`
 }
 
 type PaymentResponse struct {
@@ -0,0 +1,24 @@
+package dtos
+
+import "time"
+
+type ReviewInput struct {
+	Rating  int    `json:"rating" validate:"required,min=1,max=5" example:"5"`
+	Comment string `json:"comment" validate:"max=1000" example:"Bahannya bagus dan nyaman dipakai"`
+}
+
+type ReviewHideInput struct {
+	Reason string `json:"reason" validate:"max=255" example:"Spam"`
+}
+
+type ReviewResponse struct {
+	ReviewID     uint      `json:"review_id" example:"1"`
+	UserID       uint      `json:"user_id" example:"1"`
+	ProductID    uint      `json:"product_id" example:"1"`
+	Rating       int       `json:"rating" example:"5"`
+	Comment      string    `json:"comment" example:"Bahannya bagus dan nyaman dipakai"`
+	Hidden       bool      `json:"hidden" example:"false"`
+	HiddenReason string    `json:"hidden_reason" example:""`
+	CreatedAt    time.Time `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+	UpdatedAt    time.Time `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
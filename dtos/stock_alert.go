@@ -0,0 +1,15 @@
+package dtos
+
+import "time"
+
+type StockAlertResponse struct {
+	StockAlertID uint       `json:"stock_alert_id" example:"1"`
+	ProductID    uint       `json:"product_id" example:"1"`
+	ProductName  string     `json:"product_name" example:"Erigo"`
+	Stock        int        `json:"stock" example:"3"`
+	CurrentStock int        `json:"current_stock" example:"3"`
+	Threshold    int        `json:"threshold" example:"10"`
+	Status       string     `json:"status" example:"open"`
+	ResolvedAt   *time.Time `json:"resolved_at" example:"2023-05-17T15:07:16.504+07:00"`
+	CreatedAt    time.Time  `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
@@ -0,0 +1,36 @@
+package dtos
+
+import "time"
+
+type StockAdjustmentInput struct {
+	Quantity int    `json:"quantity" validate:"required" example:"-2"`
+	Reason   string `json:"reason" validate:"required" example:"adjustment"`
+	Note     string `json:"note" validate:"max=255" example:"Barang rusak saat stock opname"`
+	// WarehouseID is optional, without it the unassigned stock is adjusted.
+	WarehouseID *uint `json:"warehouse_id" example:"1"`
+}
+
+type StockMovementResponse struct {
+	StockMovementID uint      `json:"stock_movement_id" example:"1"`
+	ProductID       uint      `json:"product_id" example:"1"`
+	UserID          *uint     `json:"user_id" example:"1"`
+	OrderID         *uint     `json:"order_id" example:"1"`
+	WarehouseID     *uint     `json:"warehouse_id" example:"1"`
+	Reason          string    `json:"reason" example:"sale"`
+	Quantity        int       `json:"quantity" example:"-2"`
+	StockBefore     int       `json:"stock_before" example:"100"`
+	StockAfter      int       `json:"stock_after" example:"98"`
+	Note            string    `json:"note" example:""`
+	CreatedAt       time.Time `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
+
+type StockRecountResponse struct {
+	ProductID     uint   `json:"product_id" example:"1"`
+	ProductName   string `json:"product_name" example:"Erigo"`
+	Stock         int    `json:"stock" example:"98"`
+	LedgerStock   int    `json:"ledger_stock" example:"100"`
+	AssignedStock int    `json:"assigned_stock" example:"90"`
+	// Reconciled is set when an adjustment movement was recorded to bring
+	// the ledger in line with the stock.
+	Reconciled bool `json:"reconciled" example:"true"`
+}
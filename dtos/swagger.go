@@ -65,6 +65,147 @@ type CartStatusOKResponse struct {
 	Message    string       `json:"message" example:"Successfully get cart"`
 	Data       CartResponse `json:"data"`
 }
+type CartBatchStatusOKResponse struct {
+	StatusCode int            `json:"status_code" example:"200"`
+	Message    string         `json:"message" example:"Successfully updated cart"`
+	Data       []CartResponse `json:"data"`
+}
+type CartSummaryStatusOKResponse struct {
+	StatusCode int                 `json:"status_code" example:"200"`
+	Message    string              `json:"message" example:"Successfully get cart summary"`
+	Data       CartSummaryResponse `json:"data"`
+}
+type WishlistCreatedResponse struct {
+	StatusCode int              `json:"status_code" example:"201"`
+	Message    string           `json:"message" example:"Successfully created wishlist"`
+	Data       WishlistResponse `json:"data"`
+}
+
+type GetAllWishlistStatusOKResponse struct {
+	StatusCode int              `json:"status_code" example:"200"`
+	Message    string           `json:"message" example:"Successfully get wishlist"`
+	Data       WishlistResponse `json:"data"`
+	Meta       helpers.Meta     `json:"meta"`
+}
+type WishlistStatusOKResponse struct {
+	StatusCode int              `json:"status_code" example:"200"`
+	Message    string           `json:"message" example:"Successfully get wishlist"`
+	Data       WishlistResponse `json:"data"`
+}
+type ReviewCreatedResponse struct {
+	StatusCode int            `json:"status_code" example:"201"`
+	Message    string         `json:"message" example:"Successfully created review"`
+	Data       ReviewResponse `json:"data"`
+}
+
+type GetAllReviewStatusOKResponse struct {
+	StatusCode int            `json:"status_code" example:"200"`
+	Message    string         `json:"message" example:"Successfully get review"`
+	Data       ReviewResponse `json:"data"`
+	Meta       helpers.Meta   `json:"meta"`
+}
+type ReviewStatusOKResponse struct {
+	StatusCode int            `json:"status_code" example:"200"`
+	Message    string         `json:"message" example:"Successfully get review"`
+	Data       ReviewResponse `json:"data"`
+}
+type StockMovementCreatedResponse struct {
+	StatusCode int                   `json:"status_code" example:"201"`
+	Message    string                `json:"message" example:"Successfully adjusted stock"`
+	Data       StockMovementResponse `json:"data"`
+}
+
+type GetAllStockMovementStatusOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"200"`
+	Message    string                `json:"message" example:"Successfully get stock history"`
+	Data       StockMovementResponse `json:"data"`
+	Meta       helpers.Meta          `json:"meta"`
+}
+type GetAllStockAlertStatusOKResponse struct {
+	StatusCode int                `json:"status_code" example:"200"`
+	Message    string             `json:"message" example:"Successfully get stock alerts"`
+	Data       StockAlertResponse `json:"data"`
+	Meta       helpers.Meta       `json:"meta"`
+}
+type WarehouseCreatedResponse struct {
+	StatusCode int               `json:"status_code" example:"201"`
+	Message    string            `json:"message" example:"Successfully created warehouse"`
+	Data       WarehouseResponse `json:"data"`
+}
+
+type GetAllWarehouseStatusOKResponse struct {
+	StatusCode int               `json:"status_code" example:"200"`
+	Message    string            `json:"message" example:"Successfully get warehouse"`
+	Data       WarehouseResponse `json:"data"`
+	Meta       helpers.Meta      `json:"meta"`
+}
+type WarehouseStatusOKResponse struct {
+	StatusCode int               `json:"status_code" example:"200"`
+	Message    string            `json:"message" example:"Successfully get warehouse"`
+	Data       WarehouseResponse `json:"data"`
+}
+type WarehouseStockStatusOKResponse struct {
+	StatusCode int                    `json:"status_code" example:"200"`
+	Message    string                 `json:"message" example:"Successfully get warehouse stocks"`
+	Data       WarehouseStockResponse `json:"data"`
+}
+type StockTransferCreatedResponse struct {
+	StatusCode int                   `json:"status_code" example:"201"`
+	Message    string                `json:"message" example:"Successfully transferred stock"`
+	Data       StockTransferResponse `json:"data"`
+}
+type GetAllStockTransferStatusOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"200"`
+	Message    string                `json:"message" example:"Successfully get stock transfers"`
+	Data       StockTransferResponse `json:"data"`
+	Meta       helpers.Meta          `json:"meta"`
+}
+type GetAllLockoutEventStatusOKResponse struct {
+	StatusCode int                  `json:"status_code" example:"200"`
+	Message    string               `json:"message" example:"Successfully get lockout events"`
+	Data       LockoutEventResponse `json:"data"`
+	Meta       helpers.Meta         `json:"meta"`
+}
+type TwoFactorSetupStatusOKResponse struct {
+	StatusCode int                    `json:"status_code" example:"200"`
+	Message    string                 `json:"message" example:"Successfully set up two-factor authentication"`
+	Data       TwoFactorSetupResponse `json:"data"`
+}
+type RecoveryCodesStatusOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"200"`
+	Message    string                `json:"message" example:"Successfully enabled two-factor authentication"`
+	Data       RecoveryCodesResponse `json:"data"`
+}
+type APIKeyCreatedResponse struct {
+	StatusCode int            `json:"status_code" example:"201"`
+	Message    string         `json:"message" example:"Successfully created API key"`
+	Data       APIKeyResponse `json:"data"`
+}
+type GetAllAPIKeyStatusOKResponse struct {
+	StatusCode int            `json:"status_code" example:"200"`
+	Message    string         `json:"message" example:"Successfully get API keys"`
+	Data       APIKeyResponse `json:"data"`
+	Meta       helpers.Meta   `json:"meta"`
+}
+type UserExportStatusOKResponse struct {
+	StatusCode int                `json:"status_code" example:"200"`
+	Message    string             `json:"message" example:"Successfully exported user data"`
+	Data       UserExportResponse `json:"data"`
+}
+
+type GetAllAdminUserStatusOKResponse struct {
+	StatusCode int               `json:"status_code" example:"200"`
+	Message    string            `json:"message" example:"Successfully get users"`
+	Data       AdminUserResponse `json:"data"`
+	Meta       helpers.Meta      `json:"meta"`
+}
+
+type AdminUserStatusOKResponse struct {
+	StatusCode int               `json:"status_code" example:"200"`
+	Message    string            `json:"message" example:"Successfully get user"`
+	Data       AdminUserResponse `json:"data"`
+}
+
 type OrderCreatedResponse struct {
 	StatusCode int           `json:"status_code" example:"201"`
 	Message    string        `json:"message" example:"Successfully created order"`
@@ -122,6 +263,12 @@ type OrderDetailStatusOKResponse struct {
 	Data       OrderDetailResponse `json:"data"`
 }
 
+type StatusOKResponse struct {
+	StatusCode int         `json:"status_code" example:"200"`
+	Message    string      `json:"message" example:"Successfully"`
+	Data       interface{} `json:"data"`
+}
+
 type StatusOKDeletedResponse struct {
 	StatusCode int         `json:"status_code" example:"200"`
 	Message    string      `json:"message" example:"Successfully deleted"`
@@ -134,6 +281,18 @@ type BadRequestResponse struct {
 	Errors     interface{} `json:"errors"`
 }
 
+type ConflictResponse struct {
+	StatusCode int         `json:"status_code" example:"409"`
+	Message    string      `json:"message" example:"Conflict"`
+	Errors     interface{} `json:"errors"`
+}
+
+type UnprocessableEntityResponse struct {
+	StatusCode int                  `json:"status_code" example:"422"`
+	Message    string               `json:"message" example:"Invalid request data"`
+	Errors     []helpers.FieldError `json:"errors"`
+}
+
 type UnauthorizedResponse struct {
 	StatusCode int         `json:"status_code" example:"401"`
 	Message    string      `json:"message" example:"Unauthorized"`
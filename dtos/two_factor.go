@@ -0,0 +1,25 @@
+package dtos
+
+type TwoFactorCodeInput struct {
+	Code string `form:"code" json:"code" validate:"required,max=20" example:"123456"`
+}
+
+type TwoFactorDisableInput struct {
+	Password string `form:"password" json:"password" validate:"required" example:"alhamdulillah123"`
+	Code     string `form:"code" json:"code" validate:"required,max=20" example:"123456"`
+}
+
+type TwoFactorLoginInput struct {
+	ChallengeToken string `form:"challenge_token" json:"challenge_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// Code is a TOTP code or one of the recovery codes.
+	Code string `form:"code" json:"code" validate:"required,max=20" example:"123456"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/Synapsis:daniel%40gmail.com?issuer=Synapsis&secret=JBSWY3DPEHPK3PXP"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"3f9a1-0c2d4"`
+}
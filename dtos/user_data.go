@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+type UserDeleteInput struct {
+	Password string `form:"password" json:"password" validate:"required" example:"alhamdulillah123"`
+	// Code is a TOTP or recovery code, only needed when 2FA is on.
+	Code string `form:"code" json:"code" validate:"omitempty,max=20" example:"123456"`
+}
+
+type UserExportResponse struct {
+	Profile    UserInformationResponse `json:"profile"`
+	Carts      []CartResponse          `json:"carts"`
+	Orders     []OrderResponseCheckout `json:"orders"`
+	Payments   []PaymentResponse       `json:"payments"`
+	Reviews    []ReviewResponse        `json:"reviews"`
+	ExportedAt time.Time               `json:"exported_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
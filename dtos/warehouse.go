@@ -0,0 +1,46 @@
+package dtos
+
+import "time"
+
+type WarehouseInput struct {
+	Name      string  `json:"name" validate:"required,max=100" example:"Gudang Jakarta"`
+	Address   string  `json:"address" validate:"required,max=255" example:"Jl. Gatot Subroto No. 10, Jakarta"`
+	Latitude  float64 `json:"latitude" validate:"latitude" example:"-6.229728"`
+	Longitude float64 `json:"longitude" validate:"longitude" example:"106.829512"`
+}
+
+type WarehouseResponse struct {
+	WarehouseID uint      `json:"warehouse_id" example:"1"`
+	Name        string    `json:"name" example:"Gudang Jakarta"`
+	Address     string    `json:"address" example:"Jl. Gatot Subroto No. 10, Jakarta"`
+	Latitude    float64   `json:"latitude" example:"-6.229728"`
+	Longitude   float64   `json:"longitude" example:"106.829512"`
+	CreatedAt   time.Time `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
+
+type WarehouseStockResponse struct {
+	WarehouseID uint      `json:"warehouse_id" example:"1"`
+	ProductID   uint      `json:"product_id" example:"1"`
+	Stock       int       `json:"stock" example:"50"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
+
+type StockTransferInput struct {
+	ProductID       uint   `json:"product_id" validate:"required" example:"1"`
+	FromWarehouseID uint   `json:"from_warehouse_id" validate:"required" example:"1"`
+	ToWarehouseID   uint   `json:"to_warehouse_id" validate:"required,nefield=FromWarehouseID" example:"2"`
+	Quantity        int    `json:"quantity" validate:"gt=0" example:"10"`
+	Note            string `json:"note" validate:"max=255" example:"Pemerataan stok"`
+}
+
+type StockTransferResponse struct {
+	StockTransferID uint      `json:"stock_transfer_id" example:"1"`
+	ProductID       uint      `json:"product_id" example:"1"`
+	FromWarehouseID uint      `json:"from_warehouse_id" example:"1"`
+	ToWarehouseID   uint      `json:"to_warehouse_id" example:"2"`
+	Quantity        int       `json:"quantity" example:"10"`
+	UserID          *uint     `json:"user_id" example:"1"`
+	Note            string    `json:"note" example:"Pemerataan stok"`
+	CreatedAt       time.Time `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
@@ -0,0 +1,25 @@
+package dtos
+
+import "time"
+
+type WishlistInput struct {
+	ProductID         uint `json:"product_id" validate:"required" example:"1"`
+	NotifyWhenInStock bool `json:"notify_when_in_stock" example:"true"`
+}
+
+type WishlistUpdateInput struct {
+	NotifyWhenInStock bool `json:"notify_when_in_stock" example:"true"`
+}
+
+type WishlistResponse struct {
+	WishlistID        uint       `json:"wishlist_id" example:"1"`
+	UserID            uint       `json:"user_id" example:"1"`
+	ProductID         uint       `json:"product_id" example:"1"`
+	ProductName       string     `json:"product_name" example:"Erigo"`
+	Price             int        `json:"price" example:"100000"`
+	Stock             int        `json:"stock" example:"0"`
+	NotifyWhenInStock bool       `json:"notify_when_in_stock" example:"true"`
+	NotifiedAt        *time.Time `json:"notified_at" example:"2023-05-17T15:07:16.504+07:00"`
+	CreatedAt         time.Time  `json:"created_at" example:"2023-05-17T15:07:16.504+07:00"`
+	UpdatedAt         time.Time  `json:"updated_at" example:"2023-05-17T15:07:16.504+07:00"`
+}
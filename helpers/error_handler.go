@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"synapsis-backend/apperrors"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPError is what controllers return when a request fails. Message is the
+// response message, the status and errors come from Err.
+type HTTPError struct {
+	Message string
+	Err     error
+}
+
+func NewHTTPError(message string, err error) error {
+	return &HTTPError{Message: message, Err: err}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// NewHTTPErrorHandler returns the Echo error handler. It answers every error
+// in the ErrorResponse shape, with a status picked by the kind of error:
+// NotFound 404, Conflict 409, Forbidden 403, Validation 422, Internal 500
+// and 400 for errors that are not classified. Internal errors are logged
+// to log, as the response does not tell what went wrong.
+func NewHTTPErrorHandler(log *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, ctx echo.Context) {
+		if ctx.Response().Committed {
+			return
+		}
+
+		var message string
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			message = httpErr.Message
+			err = httpErr.Err
+		}
+
+		status, data := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			log.ErrorContext(ctx.Request().Context(), "request failed", slog.String("message", message), slog.Any("error", err))
+		}
+		if message == "" {
+			message = http.StatusText(status)
+		}
+
+		if ctx.Request().Method == http.MethodHead {
+			err = ctx.NoContent(status)
+		} else {
+			err = ctx.JSON(status, NewErrorResponse(status, message, data))
+		}
+		if err != nil {
+			log.ErrorContext(ctx.Request().Context(), "failed to write error response", slog.Any("error", err))
+		}
+	}
+}
+
+func errorStatus(err error) (int, interface{}) {
+	if err == nil {
+		return http.StatusBadRequest, nil
+	}
+
+	var echoErr *echo.HTTPError
+	if errors.As(err, &echoErr) {
+		if echoErr.Code == http.StatusInternalServerError {
+			return echoErr.Code, http.StatusText(echoErr.Code)
+		}
+		return echoErr.Code, fmt.Sprint(echoErr.Message)
+	}
+
+	if _, ok := err.(validator.ValidationErrors); ok {
+		return http.StatusUnprocessableEntity, GetErrorData(err)
+	}
+
+	switch apperrors.KindOf(err) {
+	case apperrors.KindNotFound:
+		return http.StatusNotFound, err.Error()
+	case apperrors.KindConflict:
+		return http.StatusConflict, err.Error()
+	case apperrors.KindForbidden:
+		return http.StatusForbidden, err.Error()
+	case apperrors.KindValidation:
+		return http.StatusUnprocessableEntity, err.Error()
+	case apperrors.KindInternal:
+		return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+	}
+	return http.StatusBadRequest, GetErrorData(err)
+}
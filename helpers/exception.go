@@ -19,9 +19,20 @@ func GetErrorData(err error) interface{} {
 
 	var errors []FieldError
 	for _, e := range errs {
+		// Keep the rule parameter, "min=8" says more than "min".
+		rule := e.ActualTag()
+		if e.Param() != "" {
+			rule += "=" + e.Param()
+		}
+		// Drop the struct name, items[0].product_id is clearer than
+		// product_id for a nested field.
+		field := e.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
 		errors = append(errors, FieldError{
-			Field: strings.ToLower(e.Field()),
-			Error: e.ActualTag(),
+			Field: strings.ToLower(field),
+			Error: rule,
 		})
 	}
 
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// phonePattern accepts local and international numbers, optionally grouped
+// with spaces or dashes, e.g. 0852-9614-3297 or +62 852 9614 3297.
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 -]{6,18}[0-9]$`)
+
+// Validator validates the request DTOs with their validate tags. It is set
+// as the Echo validator, so controllers call ctx.Validate after ctx.Bind.
+type Validator struct {
+	validate *validator.Validate
+}
+
+func NewValidator() *Validator {
+	validate := validator.New()
+
+	// Report fields by their JSON name, like the client sent them.
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	validate.RegisterValidation("phone", validatePhone)
+	validate.RegisterValidation("date", validateDate)
+
+	return &Validator{validate}
+}
+
+func (v *Validator) Validate(i interface{}) error {
+	return v.validate.Struct(i)
+}
+
+// validatePhone checks a phone number of 8 to 15 digits.
+func validatePhone(fl validator.FieldLevel) bool {
+	phone := fl.Field().String()
+	if !phonePattern.MatchString(phone) {
+		return false
+	}
+	digits := strings.NewReplacer(" ", "", "-", "", "+", "").Replace(phone)
+	return len(digits) >= 8 && len(digits) <= 15
+}
+
+// validateDate checks a YYYY-MM-DD date.
+func validateDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse("2006-01-02", fl.Field().String())
+	return err == nil
+}
@@ -0,0 +1,61 @@
+package mailers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users. Production deployments plug in an SMTP or
+// provider backed implementation; the log and file mailers are for local use.
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+type logMailer struct {
+	log *slog.Logger
+}
+
+func NewLogMailer(log *slog.Logger) Mailer {
+	return &logMailer{log}
+}
+
+func (m *logMailer) Send(ctx context.Context, mail Mail) error {
+	m.log.InfoContext(ctx, "mail",
+		slog.String("to", mail.To),
+		slog.String("subject", mail.Subject),
+		slog.String("body", mail.Body),
+	)
+	return nil
+}
+
+type fileMailer struct {
+	dir string
+}
+
+// NewFileMailer writes every mail to its own .eml file in dir, so the mails
+// can be opened with any mail client.
+func NewFileMailer(dir string) Mailer {
+	return &fileMailer{dir}
+}
+
+func (m *fileMailer) Send(ctx context.Context, mail Mail) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d.eml", now.Format("20060102T150405"), now.UnixNano())
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n", mail.To, mail.Subject, now.Format(time.RFC1123Z), mail.Body)
+
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o644)
+}
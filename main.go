@@ -1,13 +1,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"synapsis-backend/commands"
 	"synapsis-backend/configs"
 	_ "synapsis-backend/docs"
-	"synapsis-backend/routes"
-
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
-	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
 // @title           Synapsis Online Store API Documentation
@@ -33,28 +33,19 @@ import (
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
 func main() {
-
-	e := echo.New()
-
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"http:localhost:8080", "https:rest-api-7qon5jxieq-et.a.run.app"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
-	}))
-
-	db, err := configs.ConnectDB()
-	if err != nil {
-		panic(err)
+	cfg, err := configs.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
-
-	err = configs.MigrateDB(db)
 	if err != nil {
-		panic(err)
+		slog.Error("failed to load configuration", slog.Any("error", err))
+		os.Exit(1)
 	}
 
-	routes.Init(e, db)
-
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
-	e.Logger.Fatal(e.Start(":8080"))
+	// Run replaces the default logger with the configured JSON logger.
+	err = commands.Run(cfg)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		slog.Error("command failed", slog.Any("error", err))
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware records the count and latency of every request. Requests are
+// labelled with the route pattern, such as /api/v1/product/:id, so the
+// number of series stays bounded.
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			err := next(ctx)
+
+			// Write the error response now to know its status, the way
+			// middleware.Logger does. The error handler skips committed
+			// responses, so it is not written twice.
+			if err != nil {
+				ctx.Error(err)
+			}
+			status := ctx.Response().Status
+
+			// Requests that match no route have an empty path.
+			route := ctx.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			labels := []string{ctx.Request().Method, route, strconv.Itoa(status)}
+			HTTPRequests.WithLabelValues(labels...).Inc()
+			HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
@@ -2,30 +2,96 @@ package middlewares
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
 	"strings"
 	"synapsis-backend/helpers"
+	"synapsis-backend/logger"
+	"synapsis-backend/repositories"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 )
 
-func CreateToken(userID uint) (string, error) {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
+// CreateToken signs a token for the user. tokenVersion is compared with
+// User.TokenVersion on every request, so bumping the user's version revokes
+// all tokens issued before.
+func CreateToken(userID uint, role string, tokenVersion int) (string, error) {
 	claims := jwt.MapClaims{}
 	claims["authorized"] = true
 	claims["userId"] = userID
-	// claims["role"] = role
-	claims["exp"] = time.Now().Add(time.Hour * 24).Unix() // token expires after 24 hour
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(os.Getenv("SECRET_JWT")))
+	claims["role"] = role
+	claims["tokenVersion"] = tokenVersion
+	claims["exp"] = time.Now().Add(tokenTTL).Unix()
+	return signToken(claims)
+}
+
+// CreateChallengeToken signs the short-lived token returned by login when
+// the user has 2FA on. It carries no userId claim, so it is refused by
+// the auth middleware and can only be exchanged for a real token.
+func CreateChallengeToken(userID uint, tokenVersion int) (string, error) {
+	claims := jwt.MapClaims{}
+	claims["challengeUserId"] = userID
+	claims["tokenVersion"] = tokenVersion
+	claims["exp"] = time.Now().Add(5 * time.Minute).Unix()
+	return signToken(claims)
+}
+
+// GetUserFromChallengeToken returns the user ID and token version of a
+// challenge token made by CreateChallengeToken.
+func GetUserFromChallengeToken(tokenString string) (uint, int, error) {
+	token, err := parseToken(tokenString)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !token.Valid {
+		return 0, 0, errors.New("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, 0, errors.New("invalid claims")
+	}
+
+	userId, ok := claims["challengeUserId"].(float64)
+	if !ok {
+		return 0, 0, errors.New("challengeUserId claim not found")
+	}
+	tokenVersion, _ := claims["tokenVersion"].(float64)
+	return uint(userId), int(tokenVersion), nil
+}
+
+// CreateOIDCStateToken signs the state sent to an OpenID Connect provider. It
+// ties the callback to the provider and nonce of the login that started it.
+func CreateOIDCStateToken(provider, nonce string) (string, error) {
+	claims := jwt.MapClaims{}
+	claims["oidcProvider"] = provider
+	claims["nonce"] = nonce
+	claims["exp"] = time.Now().Add(10 * time.Minute).Unix()
+	return signToken(claims)
+}
+
+// ParseOIDCStateToken returns the provider and nonce of a state made by
+// CreateOIDCStateToken.
+func ParseOIDCStateToken(tokenString string) (string, string, error) {
+	token, err := parseToken(tokenString)
+	if err != nil {
+		return "", "", err
+	}
+	if !token.Valid {
+		return "", "", errors.New("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", errors.New("invalid claims")
+	}
+
+	provider, ok := claims["oidcProvider"].(string)
+	if !ok {
+		return "", "", errors.New("oidcProvider claim not found")
+	}
+	nonce, _ := claims["nonce"].(string)
+	return provider, nonce, nil
 }
 
 func GetTokenFromHeader(req *http.Request) string {
@@ -41,9 +107,7 @@ func GetTokenFromHeader(req *http.Request) string {
 }
 
 func GetUserIdFromToken(tokenString string) (uint, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SECRET_JWT")), nil
-	})
+	token, err := parseToken(tokenString)
 	if err != nil {
 		return 0, err
 	}
@@ -74,45 +138,144 @@ func JWTErrorHandler(err error, c echo.Context) error {
 	return c.JSON(http.StatusUnauthorized, customError)
 }
 
-func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+// APIKeyPrefix starts every API key, which tells them apart from JWTs in
+// the Authorization header.
+const APIKeyPrefix = "sk_"
+
+// apiKeyTouchInterval limits how often last_used_at is written for a key.
+const apiKeyTouchInterval = time.Minute
+
+// AuthMiddleware authenticates requests with a user JWT or, on routes that
+// allow it, an API key.
+type AuthMiddleware struct {
+	userRepo   repositories.UserRepository
+	apiKeyRepo repositories.APIKeyRepository
+	log        *slog.Logger
+}
+
+func NewAuthMiddleware(userRepo repositories.UserRepository, apiKeyRepo repositories.APIKeyRepository, log *slog.Logger) *AuthMiddleware {
+	return &AuthMiddleware{userRepo, apiKeyRepo, log}
+}
+
+// JWT only accepts user tokens. It rejects tokens whose user no longer
+// exists or whose sessions have been revoked.
+func (m *AuthMiddleware) JWT(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		tokenString := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("SECRET_JWT")), nil
-		})
-		if err != nil || !token.Valid {
+		if err := m.authenticateJWT(c); err != nil {
 			return JWTErrorHandler(err, c)
 		}
+		return next(c)
+	}
+}
 
-		// Set the validated token in the context
-		c.Set("user", token)
+// JWTOrAPIKey accepts a user token, or an API key granted scope. The key is
+// read from the X-API-Key header or the Authorization bearer token.
+func (m *AuthMiddleware) JWTOrAPIKey(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get("X-API-Key")
+			if bearer := GetTokenFromHeader(c.Request()); key == "" && strings.HasPrefix(bearer, APIKeyPrefix) {
+				key = bearer
+			}
+			if key == "" {
+				if err := m.authenticateJWT(c); err != nil {
+					return JWTErrorHandler(err, c)
+				}
+				return next(c)
+			}
 
-		return next(c)
+			apiKey, err := m.apiKeyRepo.GetAPIKeyByHash(c.Request().Context(), helpers.HashToken(key))
+			if err != nil || apiKey.RevokedAt != nil {
+				return JWTErrorHandler(errors.New("invalid API key"), c)
+			}
+			now := time.Now()
+			if apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt) {
+				return JWTErrorHandler(errors.New("API key has expired"), c)
+			}
+			if !apiKey.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, helpers.ErrorResponse{
+					StatusCode: http.StatusForbidden,
+					Message:    "Forbidden",
+					Errors:     "API key is missing scope " + scope,
+				})
+			}
+
+			if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+				if err := m.apiKeyRepo.TouchAPIKey(c.Request().Context(), apiKey.ID, now); err != nil {
+					m.log.ErrorContext(c.Request().Context(), "failed to update API key last use", slog.Any("error", err))
+				}
+			}
+
+			c.Set("apiKey", apiKey)
+			return next(c)
+		}
 	}
 }
 
-// func RoleMiddleware(role string) echo.MiddlewareFunc {
-// 	return func(next echo.HandlerFunc) echo.HandlerFunc {
-// 		return func(c echo.Context) error {
-// 			user := c.Get("user").(*jwt.Token)
-// 			claims := user.Claims.(jwt.MapClaims)
-// 			userRole := claims["role"].(string)
-
-// 			// fmt.Println("1", role)
-// 			// fmt.Println("2", userRole)
-
-// 			// Check if the user's role matches the required role
-// 			if userRole != role {
-// 				// Return an error response indicating unauthorized access
-// 				errorResponse := helpers.ErrorResponse{
-// 					StatusCode: http.StatusForbidden,
-// 					Message:    "Forbidden",
-// 					Errors:     "Unauthorized access",
-// 				}
-// 				return c.JSON(http.StatusForbidden, errorResponse)
-// 			}
-
-// 			return next(c)
-// 		}
-// 	}
-// }
+func (m *AuthMiddleware) authenticateJWT(c echo.Context) error {
+	tokenString := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	token, err := parseToken(tokenString)
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	userId, ok := claims["userId"].(float64)
+	if !ok {
+		return errors.New("userId claim not found")
+	}
+	// Tokens issued before versioning carry no version and count as 0.
+	tokenVersion, _ := claims["tokenVersion"].(float64)
+
+	user, err := m.userRepo.UserGetById(c.Request().Context(), uint(userId))
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if int(tokenVersion) != user.TokenVersion {
+		return errors.New("token has been revoked")
+	}
+	if user.SuspendedAt != nil {
+		return errors.New("account is suspended")
+	}
+
+	// Set the validated token in the context
+	c.Set("user", token)
+	// The logger picks the user up from the request context.
+	c.SetRequest(c.Request().WithContext(logger.WithUserID(c.Request().Context(), user.ID)))
+	return nil
+}
+
+func RoleMiddleware(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// API keys are issued by admins and already limited by their
+			// scopes in JWTOrAPIKey.
+			if c.Get("apiKey") != nil {
+				return next(c)
+			}
+
+			user, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return JWTErrorHandler(errors.New("invalid token"), c)
+			}
+			claims, _ := user.Claims.(jwt.MapClaims)
+			userRole, _ := claims["role"].(string)
+
+			// Check if the user's role matches the required role
+			if userRole != role {
+				// Return an error response indicating unauthorized access
+				errorResponse := helpers.ErrorResponse{
+					StatusCode: http.StatusForbidden,
+					Message:    "Forbidden",
+					Errors:     "Unauthorized access",
+				}
+				return c.JSON(http.StatusForbidden, errorResponse)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package middlewares
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"synapsis-backend/configs"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one key of the key set. Private is nil for keys that are only
+// kept to verify tokens signed before a rotation.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	public  crypto.PublicKey
+	private crypto.PrivateKey
+}
+
+var (
+	activeKey  *signingKey
+	keysByID   = map[string]*signingKey{}
+	hmacSecret []byte
+	tokenTTL   = 24 * time.Hour
+)
+
+// LoadSigningKeys loads the token keys from JWT_KEYS_DIR. Every <kid>.pem
+// file in it is an RSA or Ed25519 key, private or public, and is accepted to
+// verify tokens. JWT_ACTIVE_KID names the private key new tokens are signed
+// with. To rotate, add the new key, switch JWT_ACTIVE_KID and remove the old
+// key once the tokens it signed have expired.
+//
+// Without JWT_KEYS_DIR tokens are signed with HS256 and SECRET_JWT, which is
+// only meant for local development.
+func LoadSigningKeys(cfg configs.JWTConfig) error {
+	tokenTTL = cfg.TokenTTL
+	hmacSecret = []byte(cfg.Secret)
+
+	dir := cfg.KeysDir
+	if dir == "" {
+		slog.Warn("JWT_KEYS_DIR is not set, signing tokens with HS256 and SECRET_JWT")
+		activeKey = nil
+		keysByID = map[string]*signingKey{}
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*signingKey{}
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := loadSigningKey(kid, path)
+		if err != nil {
+			return fmt.Errorf("load JWT key %s: %w", path, err)
+		}
+		keys[kid] = key
+	}
+
+	active, ok := keys[cfg.ActiveKID]
+	if !ok {
+		return fmt.Errorf("JWT_ACTIVE_KID %q is not a key in %s", cfg.ActiveKID, dir)
+	}
+	if active.private == nil {
+		return fmt.Errorf("JWT_ACTIVE_KID %q is a public key, signing needs a private key", active.kid)
+	}
+
+	activeKey = active
+	keysByID = keys
+	return nil
+}
+
+func loadSigningKey(kid, path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &signingKey{kid: kid}
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.public, key.private = jwt.SigningMethodRS256, &k.PublicKey, k
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.public, key.private = jwt.SigningMethodEdDSA, k.Public(), k
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return key, nil
+}
+
+// signToken signs claims with the active key and sets its kid header.
+func signToken(claims jwt.MapClaims) (string, error) {
+	if activeKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(hmacSecret)
+	}
+
+	token := jwt.NewWithClaims(activeKey.method, claims)
+	token.Header["kid"] = activeKey.kid
+	return token.SignedString(activeKey.private)
+}
+
+// parseToken verifies a token made by signToken. The key is picked by kid
+// and the token must use that key's algorithm, so an attacker cannot switch
+// to another algorithm such as HS256 with the public key as secret.
+func parseToken(tokenString string) (*jwt.Token, error) {
+	if activeKey == nil {
+		return jwt.Parse(
+			tokenString,
+			func(token *jwt.Token) (interface{}, error) {
+				return hmacSecret, nil
+			},
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		)
+	}
+
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keysByID[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.public, nil
+	})
+}
+
+// JWKS returns the public keys as a JSON Web Key Set, so other services can
+// verify our tokens.
+func JWKS() map[string]interface{} {
+	kids := make([]string, 0, len(keysByID))
+	for kid := range keysByID {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := []map[string]string{}
+	for _, kid := range kids {
+		key := keysByID[kid]
+		jwk := map[string]string{
+			"kid": kid,
+			"use": "sig",
+			"alg": key.method.Alg(),
+		}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(public)
+		}
+		keys = append(keys, jwk)
+	}
+
+	return map[string]interface{}{"keys": keys}
+}
@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"log/slog"
+	"regexp"
+	"synapsis-backend/helpers"
+	"synapsis-backend/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// validRequestID limits the request IDs taken from clients, so the header
+// cannot be used to write arbitrary text to the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID gives every request an ID and stores it in the request context,
+// where the logger picks it up. An ID already sent in header, by a client or
+// a proxy in front of the API, is kept so it can be followed across
+// services. The ID is sent back in the same header.
+func RequestID(header string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(header)
+			if !validRequestID.MatchString(id) {
+				var err error
+				id, err = helpers.GenerateRandomToken(16)
+				if err != nil {
+					return err
+				}
+			}
+
+			c.SetRequest(req.WithContext(logger.WithRequestID(req.Context(), id)))
+			c.Response().Header().Set(header, id)
+			return next(c)
+		}
+	}
+}
+
+// RequestLogger writes an access log line for every request. Server errors
+// are logged as errors and client errors as warnings.
+func RequestLogger(log *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		HandleError:  true,
+		LogLatency:   true,
+		LogMethod:    true,
+		LogURI:       true,
+		LogRoutePath: true,
+		LogStatus:    true,
+		LogRemoteIP:  true,
+		LogUserAgent: true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			level := slog.LevelInfo
+			switch {
+			case v.Status >= 500:
+				level = slog.LevelError
+			case v.Status >= 400:
+				level = slog.LevelWarn
+			}
+
+			// The request is read again, the auth middleware replaces it to
+			// add the user to its context.
+			log.LogAttrs(c.Request().Context(), level, "request",
+				slog.String("method", v.Method),
+				slog.String("uri", v.URI),
+				slog.String("route", v.RoutePath),
+				slog.Int("status", v.Status),
+				slog.Float64("duration_ms", float64(v.Latency.Microseconds())/1000),
+				slog.String("remote_ip", v.RemoteIP),
+				slog.String("user_agent", v.UserAgent),
+			)
+			return nil
+		},
+	})
+}
+
+// Recover turns panics into 500 responses and logs them with the stack of
+// the panicking goroutine.
+func Recover(log *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RecoverWithConfig(middleware.RecoverConfig{
+		DisableStackAll: true,
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			log.ErrorContext(c.Request().Context(), "panic recovered", slog.Any("error", err), slog.String("stack", string(stack)))
+			return err
+		},
+	})
+}
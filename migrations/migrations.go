@@ -0,0 +1,322 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migrations are pairs of <version>_<name>.up.sql and <version>_<name>.down.sql
+// files in sql/, embedded in the binary. Versions are applied in order and
+// recorded in the schema_migrations table.
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// SourceDir is where `migrate create` writes new migrations, relative to the
+// repository root.
+const SourceDir = "migrations/sql"
+
+// lockID is the Postgres advisory lock held while migrating, so instances
+// started at the same time do not apply a migration twice.
+const lockID = 727011
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration is a row of schema_migrations.
+type SchemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status is a known migration and whether it has been applied.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db, migrations}, nil
+}
+
+// WithContext returns a copy of the migrator whose queries use ctx.
+func (m *Migrator) WithContext(ctx context.Context) *Migrator {
+	return &Migrator{m.db.WithContext(ctx), m.migrations}
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// applied returns the applied migrations by version. Before the first
+// migration there is no schema_migrations table and nothing is applied.
+func (m *Migrator) applied(db *gorm.DB) (map[int64]SchemaMigration, error) {
+	var exists bool
+	err := db.Raw("SELECT to_regclass(?) IS NOT NULL", SchemaMigration{}.TableName()).Scan(&exists).Error
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return map[int64]SchemaMigration{}, nil
+	}
+
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]SchemaMigration{}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Status lists every known migration with the time it was applied.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+// Each migration runs in its own transaction.
+func (m *Migrator) Up() ([]Migration, error) {
+	var done []Migration
+	for _, migration := range m.migrations {
+		ran := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			applied, err := m.lock(tx)
+			if err != nil {
+				return err
+			}
+			if _, ok := applied[migration.Version]; ok {
+				return nil
+			}
+
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns the ones rolled back.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var done []Migration
+	for i := 0; i < steps; i++ {
+		var migration *Migration
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			applied, err := m.lock(tx)
+			if err != nil {
+				return err
+			}
+
+			for j := len(m.migrations) - 1; j >= 0; j-- {
+				if _, ok := applied[m.migrations[j].Version]; ok {
+					migration = &m.migrations[j]
+					break
+				}
+			}
+			if migration == nil {
+				return nil
+			}
+
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			if migration != nil {
+				return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			return done, err
+		}
+		if migration == nil {
+			break
+		}
+		done = append(done, *migration)
+	}
+	return done, nil
+}
+
+// lock takes the migration lock for the rest of the transaction and returns
+// the applied versions as seen while holding it.
+func (m *Migrator) lock(tx *gorm.DB) (map[int64]SchemaMigration, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	return m.applied(tx)
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.applied(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// EnsureCurrent returns an error when the database schema is behind the
+// migrations embedded in the binary.
+func (m *Migrator) EnsureCurrent() error {
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		names := make([]string, len(pending))
+		for i, migration := range pending {
+			names[i] = fmt.Sprintf("%d_%s", migration.Version, migration.Name)
+		}
+		return fmt.Errorf("database schema is behind, %d pending migration(s): %s; run `migrate up`", len(pending), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// Create writes an empty up and down migration to dir, numbered after the
+// newest one there, and returns their paths.
+func Create(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	existing, err := load(os.DirFS(dir), ".")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var version int64 = 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", version, name, direction))
+		content := fmt.Sprintf("-- %s: %s\n", name, direction)
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, file)
+	}
+	return paths, nil
+}
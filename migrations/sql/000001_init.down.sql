@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "order_details";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "carts";
+DROP TABLE IF EXISTS "products";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "users";
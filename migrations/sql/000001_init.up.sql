@@ -0,0 +1,104 @@
+-- Baseline: the schema as created by GORM AutoMigrate in the last release
+-- before versioned migrations. Statements use IF NOT EXISTS so databases
+-- created by that release adopt this version without changes.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "full_name" text,
+    "email" text UNIQUE,
+    "password" text,
+    "phone_number" text,
+    "gender" text,
+    "birth_date" timestamptz,
+    "citizen" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "category" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_categories_deleted_at" ON "categories" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "category_id" bigint,
+    "name" text,
+    "description" text,
+    "price" bigint,
+    "stock" bigint,
+    "status" boolean,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_categories_products" FOREIGN KEY ("category_id") REFERENCES "categories"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_products_deleted_at" ON "products" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "carts" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "product_id" bigint,
+    "price" bigint,
+    "quantity" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_carts" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_products_carts" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_carts_deleted_at" ON "carts" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "total_price" bigint,
+    "status" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_orders" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_orders_deleted_at" ON "orders" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "order_details" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "product_id" bigint,
+    "order_id" bigint,
+    "quantity" bigint,
+    "sub_total" bigint,
+    "discount" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_order_detail" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_products_order_details" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_order_details_deleted_at" ON "order_details" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "payments" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "order_id" bigint,
+    "payment_type" text,
+    "amount" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_payment" FOREIGN KEY ("order_id") REFERENCES "orders"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_users_payments" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_payments_deleted_at" ON "payments" ("deleted_at");
@@ -0,0 +1,30 @@
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "lockout_events";
+DROP TABLE IF EXISTS "login_attempts";
+DROP TABLE IF EXISTS "password_resets";
+DROP TABLE IF EXISTS "email_verifications";
+DROP TABLE IF EXISTS "stock_transfers";
+DROP TABLE IF EXISTS "warehouse_stocks";
+DROP TABLE IF EXISTS "warehouses";
+DROP TABLE IF EXISTS "stock_alerts";
+DROP TABLE IF EXISTS "stock_movements";
+DROP TABLE IF EXISTS "reviews";
+DROP TABLE IF EXISTS "wishlists";
+ALTER TABLE "order_details" DROP COLUMN IF EXISTS "warehouse_id";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "shipping_longitude";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "shipping_latitude";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "shipping_address";
+ALTER TABLE "products" DROP COLUMN IF EXISTS "reorder_threshold";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "suspended_by";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "suspended_reason";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "suspended_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "two_factor_last_step";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "two_factor_enabled_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "two_factor_secret";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "locked_until";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "failed_logins";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "token_version";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,256 @@
+-- Everything added to the schema since the baseline. Databases created by
+-- AutoMigrate only have the baseline schema when they adopt version 1, so
+-- columns are added with IF NOT EXISTS rather than in the baseline tables.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "role" text DEFAULT 'user';
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "token_version" bigint DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "failed_logins" bigint;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "locked_until" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "two_factor_secret" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "two_factor_enabled_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "two_factor_last_step" bigint;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspended_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspended_reason" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspended_by" bigint;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "reorder_threshold" bigint;
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "shipping_address" text;
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "shipping_latitude" decimal;
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "shipping_longitude" decimal;
+ALTER TABLE "order_details" ADD COLUMN IF NOT EXISTS "warehouse_id" bigint;
+
+-- Users registered before email verification existed are treated as
+-- verified, otherwise they would be locked out of checkout. Only done when
+-- the column is new, a database that already has it has done this before.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'email_verified_at'
+    ) THEN
+        ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz;
+        UPDATE "users" SET "email_verified_at" = "created_at";
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS "wishlists" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "product_id" bigint,
+    "notify_when_in_stock" boolean,
+    "notified_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_wishlists" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_users_wishlists" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_wishlists_deleted_at" ON "wishlists" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "reviews" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "product_id" bigint,
+    "rating" bigint,
+    "comment" text,
+    "hidden" boolean,
+    "hidden_reason" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_reviews" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_users_reviews" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reviews_user_product" ON "reviews" ("user_id","product_id");
+CREATE INDEX IF NOT EXISTS "idx_reviews_deleted_at" ON "reviews" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "stock_movements" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "product_id" bigint,
+    "user_id" bigint,
+    "order_id" bigint,
+    "warehouse_id" bigint,
+    "reason" text,
+    "quantity" bigint,
+    "stock_before" bigint,
+    "stock_after" bigint,
+    "note" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_movements" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_product_id" ON "stock_movements" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_deleted_at" ON "stock_movements" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "stock_alerts" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "product_id" bigint,
+    "stock" bigint,
+    "threshold" bigint,
+    "resolved_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_stock_alerts" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_alerts_product_id" ON "stock_alerts" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_alerts_deleted_at" ON "stock_alerts" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "warehouses" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "address" text,
+    "latitude" decimal,
+    "longitude" decimal,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_warehouses_deleted_at" ON "warehouses" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "warehouse_stocks" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "warehouse_id" bigint,
+    "product_id" bigint,
+    "stock" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_warehouse_stocks" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "fk_warehouses_stocks" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_warehouse_stocks_warehouse_product" ON "warehouse_stocks" ("warehouse_id","product_id");
+CREATE INDEX IF NOT EXISTS "idx_warehouse_stocks_deleted_at" ON "warehouse_stocks" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "stock_transfers" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "product_id" bigint,
+    "from_warehouse_id" bigint,
+    "to_warehouse_id" bigint,
+    "quantity" bigint,
+    "user_id" bigint,
+    "note" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_transfers_product_id" ON "stock_transfers" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_transfers_deleted_at" ON "stock_transfers" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "email_verifications" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "token_hash" text,
+    "expires_at" timestamptz,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_email_verifications" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_email_verifications_token_hash" ON "email_verifications" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_user_id" ON "email_verifications" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_deleted_at" ON "email_verifications" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "password_resets" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "token_hash" text,
+    "expires_at" timestamptz,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_password_resets" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_resets_token_hash" ON "password_resets" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_password_resets_user_id" ON "password_resets" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_password_resets_deleted_at" ON "password_resets" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "email" text,
+    "ip" text,
+    "success" boolean,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_deleted_at" ON "login_attempts" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_ip" ON "login_attempts" ("ip");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_email" ON "login_attempts" ("email");
+
+CREATE TABLE IF NOT EXISTS "lockout_events" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "email" text,
+    "ip" text,
+    "failed_attempts" bigint,
+    "locked_until" timestamptz,
+    "unlocked_at" timestamptz,
+    "unlocked_by" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_lockout_events_user_id" ON "lockout_events" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_lockout_events_deleted_at" ON "lockout_events" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "code_hash" text,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_code_hash" ON "recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_deleted_at" ON "recovery_codes" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "user_identities" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" bigint,
+    "provider" text,
+    "subject" text,
+    "email" text,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_provider_subject" ON "user_identities" ("provider","subject");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_user_id" ON "user_identities" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_deleted_at" ON "user_identities" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "prefix" text,
+    "key_hash" text,
+    "scopes" text,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_by" bigint,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+CREATE INDEX IF NOT EXISTS "idx_api_keys_deleted_at" ON "api_keys" ("deleted_at");
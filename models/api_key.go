@@ -0,0 +1,41 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ScopeProductsRead  = "products:read"
+	ScopeProductsWrite = "products:write"
+	ScopeOrdersRead    = "orders:read"
+	ScopeOrdersWrite   = "orders:write"
+)
+
+// APIKeyScopes are the scopes an API key can be granted.
+var APIKeyScopes = []string{ScopeProductsRead, ScopeProductsWrite, ScopeOrdersRead, ScopeOrdersWrite}
+
+// APIKey lets another system call the API without a user. Only the hash of
+// the key is stored, Prefix is kept so admins can tell keys apart.
+type APIKey struct {
+	gorm.Model
+	Name       string
+	Prefix     string
+	KeyHash    string `gorm:"uniqueIndex"`
+	Scopes     string // space separated
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedBy  uint
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range strings.Fields(k.Scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
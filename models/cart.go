@@ -8,6 +8,8 @@ type Cart struct {
 	gorm.Model
 	UserID    uint
 	ProductID uint
-	Price     int
-	Quantity  int
+	// Price is the line total, the product price times Quantity when the
+	// line was last changed.
+	Price    int
+	Quantity int
 }
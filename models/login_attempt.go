@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttempt records every login, successful or not, for throttling and for
+// spotting credential stuffing. Email is kept even when no account has it.
+type LoginAttempt struct {
+	gorm.Model
+	Email   string `gorm:"index"`
+	IP      string `gorm:"index"`
+	Success bool
+}
+
+// LockoutEvent is recorded every time an account is locked after too many
+// failed logins.
+type LockoutEvent struct {
+	gorm.Model
+	UserID         uint `gorm:"index"`
+	Email          string
+	IP             string
+	FailedAttempts int
+	LockedUntil    time.Time
+	UnlockedAt     *time.Time
+	UnlockedBy     *uint
+}
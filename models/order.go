@@ -2,11 +2,26 @@ package models
 
 import "gorm.io/gorm"
 
+const (
+	OrderStatusUnpaid    = "unpaid"
+	OrderStatusPaid      = "paid"
+	OrderStatusDelivered = "delivered"
+	// OrderStatusCancelled is set when an unpaid order expires or is
+	// deleted. Its stock has been returned.
+	OrderStatusCancelled = "cancelled"
+	// OrderStatusRefunded is set when the payment of a paid order is
+	// deleted, or the order itself. Its stock has been returned.
+	OrderStatusRefunded = "refunded"
+)
+
 type Order struct {
 	gorm.Model
-	UserID      uint
-	TotalPrice  int
-	Status      string
-	Payment     Payment       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	OrderDetail []OrderDetail `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	UserID            uint
+	TotalPrice        int
+	Status            string
+	ShippingAddress   string
+	ShippingLatitude  *float64
+	ShippingLongitude *float64
+	Payment           Payment       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	OrderDetail       []OrderDetail `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
@@ -4,9 +4,10 @@ import "gorm.io/gorm"
 
 type OrderDetail struct {
 	gorm.Model
-	ProductID uint
-	OrderID   uint
-	Quantity  int
-	SubTotal  int
-	Discount  int
+	ProductID   uint
+	OrderID     uint
+	WarehouseID *uint
+	Quantity    int
+	SubTotal    int
+	Discount    int
 }
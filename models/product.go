@@ -4,12 +4,18 @@ import "gorm.io/gorm"
 
 type Product struct {
 	gorm.Model
-	CategoryID   uint
-	Name         string
-	Description  string
-	Price        int
-	Stock        int
-	Status       bool
-	Carts        []Cart        `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	OrderDetails []OrderDetail `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	CategoryID       uint
+	Name             string
+	Description      string
+	Price            int
+	Stock            int
+	ReorderThreshold int
+	Status           bool
+	Carts            []Cart           `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Wishlists        []Wishlist       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	OrderDetails     []OrderDetail    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Reviews          []Review         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Movements        []StockMovement  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	StockAlerts      []StockAlert     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	WarehouseStocks  []WarehouseStock `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+type Review struct {
+	gorm.Model
+	UserID       uint `gorm:"uniqueIndex:idx_reviews_user_product"`
+	ProductID    uint `gorm:"uniqueIndex:idx_reviews_user_product"`
+	Rating       int
+	Comment      string
+	Hidden       bool
+	HiddenReason string
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StockAlert is raised when the stock of a product drops to or below its
+// reorder threshold, and resolved once the stock is replenished above it.
+type StockAlert struct {
+	gorm.Model
+	ProductID  uint `gorm:"index"`
+	Stock      int
+	Threshold  int
+	ResolvedAt *time.Time
+}
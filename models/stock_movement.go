@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	StockMovementReasonSale         = "sale"
+	StockMovementReasonCancellation = "cancellation"
+	StockMovementReasonRefund       = "refund"
+	StockMovementReasonAdjustment   = "adjustment"
+	StockMovementReasonRestock      = "restock"
+)
+
+// StockMovement is an append-only ledger entry. Every change to
+// Product.Stock is recorded as one movement; Quantity is negative when stock
+// goes out and positive when it comes in.
+type StockMovement struct {
+	gorm.Model
+	ProductID   uint `gorm:"index"`
+	UserID      *uint
+	OrderID     *uint
+	WarehouseID *uint
+	Reason      string
+	Quantity    int
+	StockBefore int
+	StockAfter  int
+	Note        string
+}
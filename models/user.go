@@ -6,6 +6,11 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	gorm.Model
 	FullName    string
@@ -15,7 +20,30 @@ type User struct {
 	Gender      string
 	BirthDate   *time.Time
 	Citizen     string
-	Carts       []Cart    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Orders      []Order   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	Payments    []Payment `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Role        string `gorm:"default:user"`
+	// EmailVerifiedAt is nil until the user confirms their email.
+	EmailVerifiedAt *time.Time
+	// TokenVersion is bumped to revoke every token issued to the user.
+	TokenVersion int `gorm:"default:0"`
+	// FailedLogins counts failed logins since the last success or lockout.
+	FailedLogins int
+	LockedUntil  *time.Time
+	// TwoFactorSecret is set on setup, 2FA is only on once TwoFactorEnabledAt
+	// is set. TwoFactorLastStep is the time step of the last accepted code so
+	// a code cannot be used twice.
+	TwoFactorSecret    string
+	TwoFactorEnabledAt *time.Time
+	TwoFactorLastStep  int64
+	// A suspended user cannot log in and their tokens are refused until an
+	// admin reactivates the account.
+	SuspendedAt        *time.Time
+	SuspendedReason    string
+	SuspendedBy        *uint
+	Carts              []Cart              `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Wishlists          []Wishlist          `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Orders             []Order             `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Payments           []Payment           `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Reviews            []Review            `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	EmailVerifications []EmailVerification `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	PasswordResets     []PasswordReset     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 }
@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// UserIdentity links a user to an account at an external OpenID Connect
+// provider. Subject is the provider's stable ID of that account.
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint   `gorm:"index"`
+	Provider string `gorm:"uniqueIndex:idx_user_identities_provider_subject"`
+	Subject  string `gorm:"uniqueIndex:idx_user_identities_provider_subject"`
+	Email    string
+}
@@ -0,0 +1,33 @@
+package models
+
+import "gorm.io/gorm"
+
+type Warehouse struct {
+	gorm.Model
+	Name      string
+	Address   string
+	Latitude  float64
+	Longitude float64
+	Stocks    []WarehouseStock `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
+
+// WarehouseStock is the stock of one product in one warehouse. Product.Stock
+// is the total over every warehouse plus the stock not assigned to any.
+type WarehouseStock struct {
+	gorm.Model
+	WarehouseID uint `gorm:"uniqueIndex:idx_warehouse_stocks_warehouse_product"`
+	ProductID   uint `gorm:"uniqueIndex:idx_warehouse_stocks_warehouse_product"`
+	Stock       int
+}
+
+// StockTransfer moves stock of a product between warehouses. A zero
+// FromWarehouseID takes the stock from the unassigned stock of the product.
+type StockTransfer struct {
+	gorm.Model
+	ProductID       uint `gorm:"index"`
+	FromWarehouseID uint
+	ToWarehouseID   uint
+	Quantity        int
+	UserID          *uint
+	Note            string
+}
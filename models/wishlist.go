@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Wishlist struct {
+	gorm.Model
+	UserID            uint
+	ProductID         uint
+	NotifyWhenInStock bool
+	NotifiedAt        *time.Time
+}
@@ -0,0 +1,36 @@
+package notifiers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Notification is addressed to UserID, or to the staff when UserID is 0.
+type Notification struct {
+	UserID  uint
+	Subject string
+	Message string
+}
+
+// Notifier delivers notifications to users. Implementations can push them to
+// email, SMS or any other channel; LogNotifier is used when none is configured.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+type logNotifier struct {
+	log *slog.Logger
+}
+
+func NewLogNotifier(log *slog.Logger) Notifier {
+	return &logNotifier{log}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.log.InfoContext(ctx, "notification",
+		slog.Uint64("to_user_id", uint64(notification.UserID)),
+		slog.String("subject", notification.Subject),
+		slog.String("message", notification.Message),
+	)
+	return nil
+}
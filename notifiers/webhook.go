@@ -0,0 +1,59 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"synapsis-backend/logger"
+	"time"
+)
+
+type webhookNotifier struct {
+	url             string
+	requestIDHeader string
+	client          *http.Client
+}
+
+// NewWebhookNotifier posts every notification as JSON to url, e.g. a Slack
+// or chat-ops incoming webhook. Notifications sent while handling a request
+// pass its ID on in requestIDHeader.
+func NewWebhookNotifier(url, requestIDHeader string) Notifier {
+	return &webhookNotifier{
+		url:             url,
+		requestIDHeader: requestIDHeader,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"user_id": notification.UserID,
+		"subject": notification.Subject,
+		"text":    notification.Message,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if id := logger.RequestID(ctx); id != "" {
+		req.Header.Set(n.requestIDHeader, id)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
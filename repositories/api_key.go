@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	GetAllAPIKeys(ctx context.Context, page, limit int) ([]models.APIKey, int, error)
+	GetAPIKeyByID(ctx context.Context, id uint) (models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error)
+	CreateAPIKey(ctx context.Context, apiKey models.APIKey) (models.APIKey, error)
+	UpdateAPIKey(ctx context.Context, apiKey models.APIKey) (models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (r *apiKeyRepository) GetAllAPIKeys(ctx context.Context, page, limit int) ([]models.APIKey, int, error) {
+	var (
+		apiKeys []models.APIKey
+		count   int64
+	)
+	offset := (page - 1) * limit
+
+	err := conn(ctx, r.db).Model(&models.APIKey{}).Count(&count).Error
+	if err != nil {
+		return apiKeys, int(count), err
+	}
+
+	err = conn(ctx, r.db).Order("id DESC").Limit(limit).Offset(offset).Find(&apiKeys).Error
+	return apiKeys, int(count), err
+}
+
+func (r *apiKeyRepository) GetAPIKeyByID(ctx context.Context, id uint) (models.APIKey, error) {
+	var apiKey models.APIKey
+	err := conn(ctx, r.db).Where("id = ?", id).First(&apiKey).Error
+	return apiKey, err
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	var apiKey models.APIKey
+	err := conn(ctx, r.db).Where("key_hash = ?", keyHash).First(&apiKey).Error
+	return apiKey, err
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, apiKey models.APIKey) (models.APIKey, error) {
+	err := conn(ctx, r.db).Create(&apiKey).Error
+	return apiKey, err
+}
+
+func (r *apiKeyRepository) UpdateAPIKey(ctx context.Context, apiKey models.APIKey) (models.APIKey, error) {
+	err := conn(ctx, r.db).Save(&apiKey).Error
+	return apiKey, err
+}
+
+// TouchAPIKey only writes last_used_at, so it does not race with an admin
+// revoking the key.
+func (r *apiKeyRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	return conn(ctx, r.db).Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
@@ -1,17 +1,24 @@
 package repositories
 
 import (
+	"context"
 	"synapsis-backend/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CartRepository interface {
-	GetAllCarts(page, limit, user_id int) ([]models.Cart, int, error)
-	GetCartByID(id uint) (models.Cart, error)
-	CreateCart(cart models.Cart) (models.Cart, error)
-	UpdateCart(cart models.Cart) (models.Cart, error)
-	DeleteCart(cart models.Cart) error
+	GetAllCarts(ctx context.Context, page, limit, user_id int) ([]models.Cart, int, error)
+	GetCartByID(ctx context.Context, id uint) (models.Cart, error)
+	GetCartsByUserID(ctx context.Context, userID uint) ([]models.Cart, error)
+	LockCartsByUserID(ctx context.Context, userID uint) ([]models.Cart, error)
+	CreateCart(ctx context.Context, cart models.Cart) (models.Cart, error)
+	UpdateCart(ctx context.Context, cart models.Cart) (models.Cart, error)
+	DeleteCart(ctx context.Context, cart models.Cart) error
+	SaveCarts(ctx context.Context, carts []models.Cart) ([]models.Cart, error)
+	ReplaceCarts(ctx context.Context, userID uint, carts []models.Cart) ([]models.Cart, error)
+	DeleteCartsByUserID(ctx context.Context, userID uint) error
 }
 
 type cartRepository struct {
@@ -24,12 +31,12 @@ func NewCartRepository(db *gorm.DB) CartRepository {
 
 // Implementasi fungsi-fungsi dari interface ItemRepository
 
-func (r *cartRepository) GetAllCarts(page, limit, user_id int) ([]models.Cart, int, error) {
+func (r *cartRepository) GetAllCarts(ctx context.Context, page, limit, user_id int) ([]models.Cart, int, error) {
 	var (
 		carts []models.Cart
 		count int64
 	)
-	err := r.db.Find(&carts).Count(&count).Error
+	err := conn(ctx, r.db).Find(&carts).Count(&count).Error
 	if err != nil {
 		return carts, int(count), err
 	}
@@ -37,31 +44,82 @@ func (r *cartRepository) GetAllCarts(page, limit, user_id int) ([]models.Cart, i
 	offset := (page - 1) * limit
 
 	if user_id != 0 {
-		err = r.db.Where("user_id = ?", user_id).Limit(limit).Offset(offset).Find(&carts).Count(&count).Error
+		err = conn(ctx, r.db).Where("user_id = ?", user_id).Limit(limit).Offset(offset).Find(&carts).Count(&count).Error
 		return carts, int(count), err
 	}
-	err = r.db.Limit(limit).Offset(offset).Find(&carts).Error
+	err = conn(ctx, r.db).Limit(limit).Offset(offset).Find(&carts).Error
 
 	return carts, int(count), err
 }
 
-func (r *cartRepository) GetCartByID(id uint) (models.Cart, error) {
+func (r *cartRepository) GetCartByID(ctx context.Context, id uint) (models.Cart, error) {
 	var cart models.Cart
-	err := r.db.Where("id = ?", id).First(&cart).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&cart).Error
 	return cart, err
 }
 
-func (r *cartRepository) CreateCart(cart models.Cart) (models.Cart, error) {
-	err := r.db.Create(&cart).Error
+func (r *cartRepository) GetCartsByUserID(ctx context.Context, userID uint) ([]models.Cart, error) {
+	var carts []models.Cart
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("id").Find(&carts).Error
+	return carts, err
+}
+
+// LockCartsByUserID is GetCartsByUserID locking the cart until the
+// transaction of ctx ends, so concurrent changes to one cart run one after
+// the other. The user row is locked too, as an empty cart has no rows.
+func (r *cartRepository) LockCartsByUserID(ctx context.Context, userID uint) ([]models.Cart, error) {
+	db := conn(ctx, r.db)
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).Find(&models.User{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var carts []models.Cart
+	err = db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Order("id").Find(&carts).Error
+	return carts, err
+}
+
+func (r *cartRepository) CreateCart(ctx context.Context, cart models.Cart) (models.Cart, error) {
+	err := conn(ctx, r.db).Create(&cart).Error
 	return cart, err
 }
 
-func (r *cartRepository) UpdateCart(cart models.Cart) (models.Cart, error) {
-	err := r.db.Save(&cart).Error
+func (r *cartRepository) UpdateCart(ctx context.Context, cart models.Cart) (models.Cart, error) {
+	err := conn(ctx, r.db).Save(&cart).Error
 	return cart, err
 }
 
-func (r *cartRepository) DeleteCart(cart models.Cart) error {
-	err := r.db.Delete(&cart).Error
+func (r *cartRepository) DeleteCart(ctx context.Context, cart models.Cart) error {
+	err := conn(ctx, r.db).Delete(&cart).Error
+	return err
+}
+
+func (r *cartRepository) SaveCarts(ctx context.Context, carts []models.Cart) ([]models.Cart, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for i := range carts {
+			if err := tx.Save(&carts[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return carts, err
+}
+
+func (r *cartRepository) ReplaceCarts(ctx context.Context, userID uint, carts []models.Cart) ([]models.Cart, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		if len(carts) == 0 {
+			return nil
+		}
+		return tx.Create(&carts).Error
+	})
+	return carts, err
+}
+
+func (r *cartRepository) DeleteCartsByUserID(ctx context.Context, userID uint) error {
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Delete(&models.Cart{}).Error
 	return err
 }
@@ -1,17 +1,18 @@
 package repositories
 
 import (
+	"context"
 	"synapsis-backend/models"
 
 	"gorm.io/gorm"
 )
 
 type CategoryRepository interface {
-	GetAllCategorys(page, limit int) ([]models.Category, int, error)
-	GetCategoryByID(id uint) (models.Category, error)
-	CreateCategory(category models.Category) (models.Category, error)
-	UpdateCategory(category models.Category) (models.Category, error)
-	DeleteCategory(category models.Category) error
+	GetAllCategorys(ctx context.Context, page, limit int) ([]models.Category, int, error)
+	GetCategoryByID(ctx context.Context, id uint) (models.Category, error)
+	CreateCategory(ctx context.Context, category models.Category) (models.Category, error)
+	UpdateCategory(ctx context.Context, category models.Category) (models.Category, error)
+	DeleteCategory(ctx context.Context, category models.Category) error
 }
 
 type categoryRepository struct {
@@ -24,40 +25,40 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 
 // Implementasi fungsi-fungsi dari interface ItemRepository
 
-func (r *categoryRepository) GetAllCategorys(page, limit int) ([]models.Category, int, error) {
+func (r *categoryRepository) GetAllCategorys(ctx context.Context, page, limit int) ([]models.Category, int, error) {
 	var (
 		categorys []models.Category
 		count     int64
 	)
-	err := r.db.Find(&categorys).Count(&count).Error
+	err := conn(ctx, r.db).Find(&categorys).Count(&count).Error
 	if err != nil {
 		return categorys, int(count), err
 	}
 
 	offset := (page - 1) * limit
 
-	err = r.db.Limit(limit).Offset(offset).Find(&categorys).Error
+	err = conn(ctx, r.db).Limit(limit).Offset(offset).Find(&categorys).Error
 
 	return categorys, int(count), err
 }
 
-func (r *categoryRepository) GetCategoryByID(id uint) (models.Category, error) {
+func (r *categoryRepository) GetCategoryByID(ctx context.Context, id uint) (models.Category, error) {
 	var category models.Category
-	err := r.db.Where("id = ?", id).First(&category).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&category).Error
 	return category, err
 }
 
-func (r *categoryRepository) CreateCategory(category models.Category) (models.Category, error) {
-	err := r.db.Create(&category).Error
+func (r *categoryRepository) CreateCategory(ctx context.Context, category models.Category) (models.Category, error) {
+	err := conn(ctx, r.db).Create(&category).Error
 	return category, err
 }
 
-func (r *categoryRepository) UpdateCategory(category models.Category) (models.Category, error) {
-	err := r.db.Save(&category).Error
+func (r *categoryRepository) UpdateCategory(ctx context.Context, category models.Category) (models.Category, error) {
+	err := conn(ctx, r.db).Save(&category).Error
 	return category, err
 }
 
-func (r *categoryRepository) DeleteCategory(category models.Category) error {
-	err := r.db.Delete(&category).Error
+func (r *categoryRepository) DeleteCategory(ctx context.Context, category models.Category) error {
+	err := conn(ctx, r.db).Delete(&category).Error
 	return err
 }
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository interface {
+	GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (models.EmailVerification, error)
+	CreateEmailVerification(ctx context.Context, verification models.EmailVerification) (models.EmailVerification, error)
+	VerifyEmail(ctx context.Context, verification models.EmailVerification, user models.User) error
+}
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationRepository{db}
+}
+
+func (r *emailVerificationRepository) GetEmailVerificationByTokenHash(ctx context.Context, tokenHash string) (models.EmailVerification, error) {
+	var verification models.EmailVerification
+	err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&verification).Error
+	return verification, err
+}
+
+func (r *emailVerificationRepository) CreateEmailVerification(ctx context.Context, verification models.EmailVerification) (models.EmailVerification, error) {
+	err := conn(ctx, r.db).Create(&verification).Error
+	return verification, err
+}
+
+// VerifyEmail marks the token as used and the user as verified in one
+// transaction.
+func (r *emailVerificationRepository) VerifyEmail(ctx context.Context, verification models.EmailVerification, user models.User) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&verification).Update("used_at", verification.UsedAt).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("email_verified_at", user.EmailVerifiedAt).Error
+	})
+}
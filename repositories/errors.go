@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"errors"
+	"synapsis-backend/apperrors"
+
+	"gorm.io/gorm"
+)
+
+// ErrorTranslator is a GORM plugin that turns database errors into typed
+// errors: a missing record is NotFound, a unique violation is Conflict and
+// anything else is Internal. The original error stays wrapped, so
+// errors.Is(err, gorm.ErrRecordNotFound) still works.
+//
+// Unique violations are only recognised with gorm.Config.TranslateError on.
+type ErrorTranslator struct{}
+
+func (ErrorTranslator) Name() string {
+	return "apperrors:translate"
+}
+
+func (t ErrorTranslator) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	processors := []interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}{
+		callbacks.Create(),
+		callbacks.Query(),
+		callbacks.Update(),
+		callbacks.Delete(),
+		callbacks.Raw(),
+	}
+	for _, processor := range processors {
+		if err := processor.Register(t.Name(), translateError); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func translateError(db *gorm.DB) {
+	err := db.Error
+	if err == nil || apperrors.KindOf(err) != apperrors.KindUnknown {
+		return
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		db.Error = apperrors.Wrap(apperrors.KindNotFound, "Record not found", err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		db.Error = apperrors.Wrap(apperrors.KindConflict, "Record already exists", err)
+	default:
+		db.Error = apperrors.Internal(err)
+	}
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository interface {
+	CreateLoginAttempt(ctx context.Context, attempt models.LoginAttempt) (models.LoginAttempt, error)
+	GetFailedLoginsByEmail(ctx context.Context, email string, since time.Time) (int, *time.Time, error)
+	GetFailedLoginsByIP(ctx context.Context, ip string, since time.Time) (int, *time.Time, error)
+	GetAllLockoutEvents(ctx context.Context, page, limit int, userID uint) ([]models.LockoutEvent, int, error)
+	CreateLockoutEvent(ctx context.Context, event models.LockoutEvent) (models.LockoutEvent, error)
+	UnlockUser(ctx context.Context, user models.User, adminID uint) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (r *loginAttemptRepository) CreateLoginAttempt(ctx context.Context, attempt models.LoginAttempt) (models.LoginAttempt, error) {
+	err := conn(ctx, r.db).Create(&attempt).Error
+	return attempt, err
+}
+
+// GetFailedLoginsByEmail returns the number of failed logins for the email
+// since the given time and its last successful login, and when the latest
+// failure happened.
+func (r *loginAttemptRepository) GetFailedLoginsByEmail(ctx context.Context, email string, since time.Time) (int, *time.Time, error) {
+	var lastSuccess models.LoginAttempt
+	err := conn(ctx, r.db).Where("email = ? AND success = ?", email, true).Order("created_at DESC").Limit(1).Find(&lastSuccess).Error
+	if err != nil {
+		return 0, nil, err
+	}
+	if lastSuccess.ID != 0 && lastSuccess.CreatedAt.After(since) {
+		since = lastSuccess.CreatedAt
+	}
+
+	return r.getFailedLogins(conn(ctx, r.db).Where("email = ?", email), since)
+}
+
+// GetFailedLoginsByIP returns the number of failed logins from the IP since
+// the given time, and when the latest one happened.
+func (r *loginAttemptRepository) GetFailedLoginsByIP(ctx context.Context, ip string, since time.Time) (int, *time.Time, error) {
+	return r.getFailedLogins(conn(ctx, r.db).Where("ip = ?", ip), since)
+}
+
+func (r *loginAttemptRepository) getFailedLogins(query *gorm.DB, since time.Time) (int, *time.Time, error) {
+	var result struct {
+		Count  int
+		LastAt *time.Time
+	}
+	err := query.Model(&models.LoginAttempt{}).
+		Select("COUNT(*) AS count, MAX(created_at) AS last_at").
+		Where("success = ? AND created_at > ?", false, since).
+		Scan(&result).Error
+	return result.Count, result.LastAt, err
+}
+
+func (r *loginAttemptRepository) GetAllLockoutEvents(ctx context.Context, page, limit int, userID uint) ([]models.LockoutEvent, int, error) {
+	var (
+		events []models.LockoutEvent
+		count  int64
+	)
+	offset := (page - 1) * limit
+
+	query := conn(ctx, r.db).Model(&models.LockoutEvent{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	err := query.Count(&count).Error
+	if err != nil {
+		return events, int(count), err
+	}
+
+	err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&events).Error
+	return events, int(count), err
+}
+
+func (r *loginAttemptRepository) CreateLockoutEvent(ctx context.Context, event models.LockoutEvent) (models.LockoutEvent, error) {
+	err := conn(ctx, r.db).Create(&event).Error
+	return event, err
+}
+
+// UnlockUser lifts the lock of the user and closes its open lockout events
+// in one transaction.
+func (r *loginAttemptRepository) UnlockUser(ctx context.Context, user models.User, adminID uint) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.LockoutEvent{}).
+			Where("user_id = ? AND unlocked_at IS NULL", user.ID).
+			Updates(map[string]interface{}{"unlocked_at": now, "unlocked_by": adminID}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&user).Updates(map[string]interface{}{"locked_until": nil, "failed_logins": 0}).Error
+	})
+}
@@ -1,17 +1,24 @@
 package repositories
 
 import (
+	"context"
 	"synapsis-backend/models"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type OrderRepository interface {
-	GetAllOrders(page, limit int, status string) ([]models.Order, int, error)
-	GetOrderByID(id uint) (models.Order, error)
-	CreateOrder(order models.Order) (models.Order, error)
-	UpdateOrder(order models.Order) (models.Order, error)
-	DeleteOrder(order models.Order) error
+	GetAllOrders(ctx context.Context, page, limit int, status string) ([]models.Order, int, error)
+	GetOrderByID(ctx context.Context, id uint) (models.Order, error)
+	GetOrdersByUserID(ctx context.Context, page, limit int, userID uint) ([]models.Order, int, error)
+	CreateOrder(ctx context.Context, order models.Order) (models.Order, error)
+	UpdateOrder(ctx context.Context, order models.Order) (models.Order, error)
+	DeleteOrder(ctx context.Context, order models.Order) error
+	GetUnpaidOrdersCreatedBefore(ctx context.Context, before time.Time) ([]models.Order, error)
+	CancelUnpaidOrder(ctx context.Context, order models.Order) (bool, error)
+	RefundPaidOrder(ctx context.Context, order models.Order) (bool, error)
+	PayUnpaidOrder(ctx context.Context, order models.Order) (bool, error)
 }
 
 type orderRepository struct {
@@ -24,12 +31,12 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 
 // Implementasi fungsi-fungsi dari interface ItemRepository
 
-func (r *orderRepository) GetAllOrders(page, limit int, status string) ([]models.Order, int, error) {
+func (r *orderRepository) GetAllOrders(ctx context.Context, page, limit int, status string) ([]models.Order, int, error) {
 	var (
 		orders []models.Order
 		count  int64
 	)
-	err := r.db.Find(&orders).Count(&count).Error
+	err := conn(ctx, r.db).Find(&orders).Count(&count).Error
 	if err != nil {
 		return orders, int(count), err
 	}
@@ -37,31 +44,84 @@ func (r *orderRepository) GetAllOrders(page, limit int, status string) ([]models
 	offset := (page - 1) * limit
 
 	if status != "" {
-		err = r.db.Where("status = ?", status).Limit(limit).Offset(offset).Find(&orders).Count(&count).Error
+		err = conn(ctx, r.db).Where("status = ?", status).Limit(limit).Offset(offset).Find(&orders).Count(&count).Error
 		return orders, int(count), err
 	}
-	err = r.db.Limit(limit).Offset(offset).Find(&orders).Error
+	err = conn(ctx, r.db).Limit(limit).Offset(offset).Find(&orders).Error
 
 	return orders, int(count), err
 }
 
-func (r *orderRepository) GetOrderByID(id uint) (models.Order, error) {
+func (r *orderRepository) GetOrderByID(ctx context.Context, id uint) (models.Order, error) {
 	var order models.Order
-	err := r.db.Where("id = ?", id).First(&order).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&order).Error
 	return order, err
 }
 
-func (r *orderRepository) CreateOrder(order models.Order) (models.Order, error) {
-	err := r.db.Create(&order).Error
+func (r *orderRepository) GetOrdersByUserID(ctx context.Context, page, limit int, userID uint) ([]models.Order, int, error) {
+	var (
+		orders []models.Order
+		count  int64
+	)
+	offset := (page - 1) * limit
+
+	query := conn(ctx, r.db).Model(&models.Order{}).Where("user_id = ?", userID)
+	err := query.Count(&count).Error
+	if err != nil {
+		return orders, int(count), err
+	}
+
+	err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&orders).Error
+	return orders, int(count), err
+}
+
+func (r *orderRepository) CreateOrder(ctx context.Context, order models.Order) (models.Order, error) {
+	err := conn(ctx, r.db).Create(&order).Error
 	return order, err
 }
 
-func (r *orderRepository) UpdateOrder(order models.Order) (models.Order, error) {
-	err := r.db.Save(&order).Error
+func (r *orderRepository) UpdateOrder(ctx context.Context, order models.Order) (models.Order, error) {
+	err := conn(ctx, r.db).Save(&order).Error
 	return order, err
 }
 
-func (r *orderRepository) DeleteOrder(order models.Order) error {
-	err := r.db.Delete(&order).Error
+func (r *orderRepository) DeleteOrder(ctx context.Context, order models.Order) error {
+	err := conn(ctx, r.db).Delete(&order).Error
 	return err
 }
+
+func (r *orderRepository) GetUnpaidOrdersCreatedBefore(ctx context.Context, before time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	err := conn(ctx, r.db).
+		Where("status = ? AND created_at < ?", models.OrderStatusUnpaid, before).
+		Order("id").
+		Find(&orders).Error
+	return orders, err
+}
+
+// CancelUnpaidOrder cancels the order only if it is still unpaid and reports
+// whether it did, so an order paid in the meantime is left alone.
+func (r *orderRepository) CancelUnpaidOrder(ctx context.Context, order models.Order) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.Order{}).
+		Where("id = ? AND status = ?", order.ID, models.OrderStatusUnpaid).
+		Update("status", models.OrderStatusCancelled)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RefundPaidOrder marks the order refunded only if it is still paid and
+// reports whether it did, so an order is never refunded twice.
+func (r *orderRepository) RefundPaidOrder(ctx context.Context, order models.Order) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.Order{}).
+		Where("id = ? AND status = ?", order.ID, models.OrderStatusPaid).
+		Update("status", models.OrderStatusRefunded)
+	return result.RowsAffected == 1, result.Error
+}
+
+// PayUnpaidOrder marks the order paid only if it is still unpaid and reports
+// whether it did, so an order is never paid twice or after it expired.
+func (r *orderRepository) PayUnpaidOrder(ctx context.Context, order models.Order) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.Order{}).
+		Where("id = ? AND status = ?", order.ID, models.OrderStatusUnpaid).
+		Update("status", models.OrderStatusPaid)
+	return result.RowsAffected == 1, result.Error
+}
@@ -1,17 +1,18 @@
 package repositories
 
 import (
+	"context"
 	"synapsis-backend/models"
 
 	"gorm.io/gorm"
 )
 
 type OrderDetailRepository interface {
-	GetAllOrderDetails(page, limit, user_id int) ([]models.OrderDetail, int, error)
-	GetOrderDetailByID(id uint) (models.OrderDetail, error)
-	CreateOrderDetail(orderDetail models.OrderDetail) (models.OrderDetail, error)
-	UpdateOrderDetail(orderDetail models.OrderDetail) (models.OrderDetail, error)
-	DeleteOrderDetail(orderDetail models.OrderDetail) error
+	GetAllOrderDetails(ctx context.Context, page, limit, user_id int) ([]models.OrderDetail, int, error)
+	GetOrderDetailByID(ctx context.Context, id uint) (models.OrderDetail, error)
+	CreateOrderDetail(ctx context.Context, orderDetail models.OrderDetail) (models.OrderDetail, error)
+	UpdateOrderDetail(ctx context.Context, orderDetail models.OrderDetail) (models.OrderDetail, error)
+	DeleteOrderDetail(ctx context.Context, orderDetail models.OrderDetail) error
 }
 
 type orderDetailRepository struct {
@@ -24,12 +25,12 @@ func NewOrderDetailRepository(db *gorm.DB) OrderDetailRepository {
 
 // Implementasi fungsi-fungsi dari interface ItemRepository
 
-func (r *orderDetailRepository) GetAllOrderDetails(page, limit, user_id int) ([]models.OrderDetail, int, error) {
+func (r *orderDetailRepository) GetAllOrderDetails(ctx context.Context, page, limit, user_id int) ([]models.OrderDetail, int, error) {
 	var (
 		orderDetails []models.OrderDetail
 		count        int64
 	)
-	err := r.db.Find(&orderDetails).Count(&count).Error
+	err := conn(ctx, r.db).Find(&orderDetails).Count(&count).Error
 	if err != nil {
 		return orderDetails, int(count), err
 	}
@@ -37,31 +38,31 @@ func (r *orderDetailRepository) GetAllOrderDetails(page, limit, user_id int) ([]
 	offset := (page - 1) * limit
 
 	if user_id != 0 {
-		err = r.db.Where("user_id = ?", user_id).Limit(limit).Offset(offset).Find(&orderDetails).Count(&count).Error
+		err = conn(ctx, r.db).Where("user_id = ?", user_id).Limit(limit).Offset(offset).Find(&orderDetails).Count(&count).Error
 		return orderDetails, int(count), err
 	}
-	err = r.db.Limit(limit).Offset(offset).Find(&orderDetails).Error
+	err = conn(ctx, r.db).Limit(limit).Offset(offset).Find(&orderDetails).Error
 
 	return orderDetails, int(count), err
 }
 
-func (r *orderDetailRepository) GetOrderDetailByID(id uint) (models.OrderDetail, error) {
+func (r *orderDetailRepository) GetOrderDetailByID(ctx context.Context, id uint) (models.OrderDetail, error) {
 	var orderDetail models.OrderDetail
-	err := r.db.Where("id = ?", id).First(&orderDetail).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&orderDetail).Error
 	return orderDetail, err
 }
 
-func (r *orderDetailRepository) CreateOrderDetail(orderDetail models.OrderDetail) (models.OrderDetail, error) {
-	err := r.db.Create(&orderDetail).Error
+func (r *orderDetailRepository) CreateOrderDetail(ctx context.Context, orderDetail models.OrderDetail) (models.OrderDetail, error) {
+	err := conn(ctx, r.db).Create(&orderDetail).Error
 	return orderDetail, err
 }
 
-func (r *orderDetailRepository) UpdateOrderDetail(orderDetail models.OrderDetail) (models.OrderDetail, error) {
-	err := r.db.Save(&orderDetail).Error
+func (r *orderDetailRepository) UpdateOrderDetail(ctx context.Context, orderDetail models.OrderDetail) (models.OrderDetail, error) {
+	err := conn(ctx, r.db).Save(&orderDetail).Error
 	return orderDetail, err
 }
 
-func (r *orderDetailRepository) DeleteOrderDetail(orderDetail models.OrderDetail) error {
-	err := r.db.Delete(&orderDetail).Error
+func (r *orderDetailRepository) DeleteOrderDetail(ctx context.Context, orderDetail models.OrderDetail) error {
+	err := conn(ctx, r.db).Delete(&orderDetail).Error
 	return err
 }
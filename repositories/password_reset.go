@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	GetPasswordResetByTokenHash(ctx context.Context, tokenHash string) (models.PasswordReset, error)
+	CreatePasswordReset(ctx context.Context, reset models.PasswordReset) (models.PasswordReset, error)
+	ResetPassword(ctx context.Context, reset models.PasswordReset, user models.User) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db}
+}
+
+func (r *passwordResetRepository) GetPasswordResetByTokenHash(ctx context.Context, tokenHash string) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&reset).Error
+	return reset, err
+}
+
+func (r *passwordResetRepository) CreatePasswordReset(ctx context.Context, reset models.PasswordReset) (models.PasswordReset, error) {
+	err := conn(ctx, r.db).Create(&reset).Error
+	return reset, err
+}
+
+// ResetPassword stores the new password and token version of the user and
+// uses up every outstanding reset token of the user in one transaction.
+func (r *passwordResetRepository) ResetPassword(ctx context.Context, reset models.PasswordReset, user models.User) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordReset{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", reset.UsedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		// Another request redeemed the token in the meantime.
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Model(&models.PasswordReset{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", reset.UsedAt).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"password":      user.Password,
+			"token_version": user.TokenVersion,
+		}).Error
+	})
+}
@@ -1,17 +1,19 @@
 package repositories
 
 import (
+	"context"
 	"synapsis-backend/models"
 
 	"gorm.io/gorm"
 )
 
 type PaymentRepository interface {
-	GetAllPayments(page, limit, category_id int) ([]models.Payment, int, error)
-	GetPaymentByID(id uint) (models.Payment, error)
-	CreatePayment(payment models.Payment) (models.Payment, error)
-	UpdatePayment(payment models.Payment) (models.Payment, error)
-	DeletePayment(payment models.Payment) error
+	GetAllPayments(ctx context.Context, page, limit, category_id int) ([]models.Payment, int, error)
+	GetPaymentByID(ctx context.Context, id uint) (models.Payment, error)
+	GetPaymentsByUserID(ctx context.Context, page, limit int, userID uint) ([]models.Payment, int, error)
+	CreatePayment(ctx context.Context, payment models.Payment) (models.Payment, error)
+	UpdatePayment(ctx context.Context, payment models.Payment) (models.Payment, error)
+	DeletePayment(ctx context.Context, payment models.Payment) error
 }
 
 type paymentRepository struct {
@@ -24,12 +26,12 @@ func NewPaymentRepository(db *gorm.DB) PaymentRepository {
 
 // Implementasi fungsi-fungsi dari interface ItemRepository
 
-func (r *paymentRepository) GetAllPayments(page, limit, category_id int) ([]models.Payment, int, error) {
+func (r *paymentRepository) GetAllPayments(ctx context.Context, page, limit, category_id int) ([]models.Payment, int, error) {
 	var (
 		payments []models.Payment
 		count    int64
 	)
-	err := r.db.Find(&payments).Count(&count).Error
+	err := conn(ctx, r.db).Find(&payments).Count(&count).Error
 	if err != nil {
 		return payments, int(count), err
 	}
@@ -37,31 +39,48 @@ func (r *paymentRepository) GetAllPayments(page, limit, category_id int) ([]mode
 	offset := (page - 1) * limit
 
 	if category_id != 0 {
-		err = r.db.Where("category_id = ?", category_id).Limit(limit).Offset(offset).Find(&payments).Count(&count).Error
+		err = conn(ctx, r.db).Where("category_id = ?", category_id).Limit(limit).Offset(offset).Find(&payments).Count(&count).Error
 		return payments, int(count), err
 	}
-	err = r.db.Limit(limit).Offset(offset).Find(&payments).Error
+	err = conn(ctx, r.db).Limit(limit).Offset(offset).Find(&payments).Error
 
 	return payments, int(count), err
 }
 
-func (r *paymentRepository) GetPaymentByID(id uint) (models.Payment, error) {
+func (r *paymentRepository) GetPaymentByID(ctx context.Context, id uint) (models.Payment, error) {
 	var payment models.Payment
-	err := r.db.Where("id = ?", id).First(&payment).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&payment).Error
 	return payment, err
 }
 
-func (r *paymentRepository) CreatePayment(payment models.Payment) (models.Payment, error) {
-	err := r.db.Create(&payment).Error
+func (r *paymentRepository) GetPaymentsByUserID(ctx context.Context, page, limit int, userID uint) ([]models.Payment, int, error) {
+	var (
+		payments []models.Payment
+		count    int64
+	)
+	offset := (page - 1) * limit
+
+	query := conn(ctx, r.db).Model(&models.Payment{}).Where("user_id = ?", userID)
+	err := query.Count(&count).Error
+	if err != nil {
+		return payments, int(count), err
+	}
+
+	err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&payments).Error
+	return payments, int(count), err
+}
+
+func (r *paymentRepository) CreatePayment(ctx context.Context, payment models.Payment) (models.Payment, error) {
+	err := conn(ctx, r.db).Create(&payment).Error
 	return payment, err
 }
 
-func (r *paymentRepository) UpdatePayment(payment models.Payment) (models.Payment, error) {
-	err := r.db.Save(&payment).Error
+func (r *paymentRepository) UpdatePayment(ctx context.Context, payment models.Payment) (models.Payment, error) {
+	err := conn(ctx, r.db).Save(&payment).Error
 	return payment, err
 }
 
-func (r *paymentRepository) DeletePayment(payment models.Payment) error {
-	err := r.db.Delete(&payment).Error
+func (r *paymentRepository) DeletePayment(ctx context.Context, payment models.Payment) error {
+	err := conn(ctx, r.db).Delete(&payment).Error
 	return err
 }
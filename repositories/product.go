@@ -1,17 +1,19 @@
 package repositories
 
 import (
+	"context"
 	"synapsis-backend/models"
 
 	"gorm.io/gorm"
 )
 
 type ProductRepository interface {
-	GetAllProducts(page, limit, category_id int) ([]models.Product, int, error)
-	GetProductByID(id uint) (models.Product, error)
-	CreateProduct(product models.Product) (models.Product, error)
-	UpdateProduct(product models.Product) (models.Product, error)
-	DeleteProduct(product models.Product) error
+	GetAllProducts(ctx context.Context, page, limit, category_id int) ([]models.Product, int, error)
+	GetProductByID(ctx context.Context, id uint) (models.Product, error)
+	CreateProduct(ctx context.Context, product models.Product) (models.Product, error)
+	UpdateProduct(ctx context.Context, product models.Product) (models.Product, error)
+	DeleteProduct(ctx context.Context, product models.Product) error
+	GetLowStockProducts(ctx context.Context) ([]models.Product, error)
 }
 
 type productRepository struct {
@@ -24,12 +26,12 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 
 // Implementasi fungsi-fungsi dari interface ItemRepository
 
-func (r *productRepository) GetAllProducts(page, limit, category_id int) ([]models.Product, int, error) {
+func (r *productRepository) GetAllProducts(ctx context.Context, page, limit, category_id int) ([]models.Product, int, error) {
 	var (
 		products []models.Product
 		count    int64
 	)
-	err := r.db.Find(&products).Count(&count).Error
+	err := conn(ctx, r.db).Find(&products).Count(&count).Error
 	if err != nil {
 		return products, int(count), err
 	}
@@ -37,31 +39,39 @@ func (r *productRepository) GetAllProducts(page, limit, category_id int) ([]mode
 	offset := (page - 1) * limit
 
 	if category_id != 0 {
-		err = r.db.Where("category_id = ?", category_id).Limit(limit).Offset(offset).Find(&products).Count(&count).Error
+		err = conn(ctx, r.db).Where("category_id = ?", category_id).Limit(limit).Offset(offset).Find(&products).Count(&count).Error
 		return products, int(count), err
 	}
-	err = r.db.Limit(limit).Offset(offset).Find(&products).Error
+	err = conn(ctx, r.db).Limit(limit).Offset(offset).Find(&products).Error
 
 	return products, int(count), err
 }
 
-func (r *productRepository) GetProductByID(id uint) (models.Product, error) {
+func (r *productRepository) GetProductByID(ctx context.Context, id uint) (models.Product, error) {
 	var product models.Product
-	err := r.db.Where("id = ?", id).First(&product).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&product).Error
 	return product, err
 }
 
-func (r *productRepository) CreateProduct(product models.Product) (models.Product, error) {
-	err := r.db.Create(&product).Error
+func (r *productRepository) CreateProduct(ctx context.Context, product models.Product) (models.Product, error) {
+	err := conn(ctx, r.db).Create(&product).Error
 	return product, err
 }
 
-func (r *productRepository) UpdateProduct(product models.Product) (models.Product, error) {
-	err := r.db.Save(&product).Error
+// UpdateProduct never writes Stock; stock changes go through
+// StockMovementRepository.ApplyStockMovement so that they are recorded.
+func (r *productRepository) UpdateProduct(ctx context.Context, product models.Product) (models.Product, error) {
+	err := conn(ctx, r.db).Omit("Stock").Save(&product).Error
 	return product, err
 }
 
-func (r *productRepository) DeleteProduct(product models.Product) error {
-	err := r.db.Delete(&product).Error
+func (r *productRepository) DeleteProduct(ctx context.Context, product models.Product) error {
+	err := conn(ctx, r.db).Delete(&product).Error
 	return err
 }
+
+func (r *productRepository) GetLowStockProducts(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	err := conn(ctx, r.db).Where("reorder_threshold > 0 AND stock <= reorder_threshold").Find(&products).Error
+	return products, err
+}
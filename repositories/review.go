@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type ProductRating struct {
+	ProductID     uint
+	AverageRating float64
+	ReviewCount   int
+}
+
+type ReviewRepository interface {
+	GetAllReviews(ctx context.Context, page, limit int, productID uint, hidden *bool) ([]models.Review, int, error)
+	GetReviewByID(ctx context.Context, id uint) (models.Review, error)
+	GetReviewByUserAndProduct(ctx context.Context, userID, productID uint) (models.Review, error)
+	CreateReview(ctx context.Context, review models.Review) (models.Review, error)
+	UpdateReview(ctx context.Context, review models.Review) (models.Review, error)
+	GetProductRatings(ctx context.Context, productIDs []uint) (map[uint]ProductRating, error)
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db}
+}
+
+func (r *reviewRepository) GetAllReviews(ctx context.Context, page, limit int, productID uint, hidden *bool) ([]models.Review, int, error) {
+	var (
+		reviews []models.Review
+		count   int64
+	)
+	offset := (page - 1) * limit
+
+	query := conn(ctx, r.db).Model(&models.Review{})
+	if productID != 0 {
+		query = query.Where("product_id = ?", productID)
+	}
+	if hidden != nil {
+		query = query.Where("hidden = ?", *hidden)
+	}
+
+	err := query.Count(&count).Error
+	if err != nil {
+		return reviews, int(count), err
+	}
+
+	err = query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&reviews).Error
+	return reviews, int(count), err
+}
+
+func (r *reviewRepository) GetReviewByID(ctx context.Context, id uint) (models.Review, error) {
+	var review models.Review
+	err := conn(ctx, r.db).Where("id = ?", id).First(&review).Error
+	return review, err
+}
+
+func (r *reviewRepository) GetReviewByUserAndProduct(ctx context.Context, userID, productID uint) (models.Review, error) {
+	var review models.Review
+	err := conn(ctx, r.db).Where("user_id = ? AND product_id = ?", userID, productID).First(&review).Error
+	return review, err
+}
+
+func (r *reviewRepository) CreateReview(ctx context.Context, review models.Review) (models.Review, error) {
+	err := conn(ctx, r.db).Create(&review).Error
+	return review, err
+}
+
+func (r *reviewRepository) UpdateReview(ctx context.Context, review models.Review) (models.Review, error) {
+	err := conn(ctx, r.db).Save(&review).Error
+	return review, err
+}
+
+// GetProductRatings returns the average rating and review count of every
+// product in productIDs, ignoring hidden reviews. Products without reviews
+// are not in the result.
+func (r *reviewRepository) GetProductRatings(ctx context.Context, productIDs []uint) (map[uint]ProductRating, error) {
+	ratings := make(map[uint]ProductRating, len(productIDs))
+	if len(productIDs) == 0 {
+		return ratings, nil
+	}
+
+	var rows []ProductRating
+	err := conn(ctx, r.db).Model(&models.Review{}).
+		Select("product_id, AVG(rating) AS average_rating, COUNT(*) AS review_count").
+		Where("product_id IN ? AND hidden = ?", productIDs, false).
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return ratings, err
+	}
+
+	for _, row := range rows {
+		ratings[row.ProductID] = row
+	}
+	return ratings, nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type StockAlertRepository interface {
+	GetAllStockAlerts(ctx context.Context, page, limit int, status string) ([]models.StockAlert, int, error)
+	GetOpenStockAlert(ctx context.Context, productID uint) (models.StockAlert, error)
+	GetOpenStockAlerts(ctx context.Context) ([]models.StockAlert, error)
+	CreateStockAlert(ctx context.Context, alert models.StockAlert) (models.StockAlert, error)
+	UpdateStockAlert(ctx context.Context, alert models.StockAlert) (models.StockAlert, error)
+}
+
+type stockAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewStockAlertRepository(db *gorm.DB) StockAlertRepository {
+	return &stockAlertRepository{db}
+}
+
+// GetAllStockAlerts filters alerts by status, which is "open", "resolved" or
+// empty for every alert.
+func (r *stockAlertRepository) GetAllStockAlerts(ctx context.Context, page, limit int, status string) ([]models.StockAlert, int, error) {
+	var (
+		alerts []models.StockAlert
+		count  int64
+	)
+	offset := (page - 1) * limit
+
+	query := conn(ctx, r.db).Model(&models.StockAlert{})
+	switch status {
+	case "open":
+		query = query.Where("resolved_at IS NULL")
+	case "resolved":
+		query = query.Where("resolved_at IS NOT NULL")
+	}
+
+	err := query.Count(&count).Error
+	if err != nil {
+		return alerts, int(count), err
+	}
+
+	err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&alerts).Error
+	return alerts, int(count), err
+}
+
+func (r *stockAlertRepository) GetOpenStockAlert(ctx context.Context, productID uint) (models.StockAlert, error) {
+	var alert models.StockAlert
+	err := conn(ctx, r.db).Where("product_id = ? AND resolved_at IS NULL", productID).First(&alert).Error
+	return alert, err
+}
+
+func (r *stockAlertRepository) GetOpenStockAlerts(ctx context.Context) ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+	err := conn(ctx, r.db).Where("resolved_at IS NULL").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *stockAlertRepository) CreateStockAlert(ctx context.Context, alert models.StockAlert) (models.StockAlert, error) {
+	err := conn(ctx, r.db).Create(&alert).Error
+	return alert, err
+}
+
+func (r *stockAlertRepository) UpdateStockAlert(ctx context.Context, alert models.StockAlert) (models.StockAlert, error) {
+	err := conn(ctx, r.db).Save(&alert).Error
+	return alert, err
+}
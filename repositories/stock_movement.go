@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("Insufficient stock")
+
+type StockMovementRepository interface {
+	GetStockMovementsByProductID(ctx context.Context, page, limit int, productID uint) ([]models.StockMovement, int, error)
+	ApplyStockMovement(ctx context.Context, movement models.StockMovement) (models.StockMovement, error)
+	SetStockLevel(ctx context.Context, movement models.StockMovement, stock int) (models.StockMovement, error)
+	GetStockDiscrepancies(ctx context.Context) ([]StockDiscrepancy, error)
+	ReconcileStockLedger(ctx context.Context, productID uint, note string) (models.StockMovement, error)
+	GetOrderHeldStock(ctx context.Context, orderID uint) ([]HeldStock, error)
+	HasPurchasedProduct(ctx context.Context, userID, productID uint, statuses []string) (bool, error)
+}
+
+// StockDiscrepancy is a product whose stock does not match its ledger or is
+// less than the stock assigned to its warehouses.
+type StockDiscrepancy struct {
+	ProductID   uint
+	ProductName string
+	Stock       int
+	// LedgerStock is the stock after the last movement of the product.
+	LedgerStock   int
+	AssignedStock int
+}
+
+// HeldStock is stock of a product taken from a warehouse, or from the
+// unassigned stock when WarehouseID is nil, by an order and not returned yet.
+type HeldStock struct {
+	ProductID   uint
+	WarehouseID *uint
+	Quantity    int
+}
+
+type stockMovementRepository struct {
+	db *gorm.DB
+}
+
+func NewStockMovementRepository(db *gorm.DB) StockMovementRepository {
+	return &stockMovementRepository{db}
+}
+
+func (r *stockMovementRepository) GetStockMovementsByProductID(ctx context.Context, page, limit int, productID uint) ([]models.StockMovement, int, error) {
+	var (
+		movements []models.StockMovement
+		count     int64
+	)
+	offset := (page - 1) * limit
+
+	err := conn(ctx, r.db).Model(&models.StockMovement{}).Where("product_id = ?", productID).Count(&count).Error
+	if err != nil {
+		return movements, int(count), err
+	}
+
+	err = conn(ctx, r.db).Where("product_id = ?", productID).Order("id DESC").Limit(limit).Offset(offset).Find(&movements).Error
+	return movements, int(count), err
+}
+
+// ApplyStockMovement locks the product row, applies movement.Quantity to its
+// stock and appends the movement to the ledger in one transaction, nested in
+// the transaction of ctx when there is one. When the
+// movement has a WarehouseID the stock of that warehouse changes as well,
+// otherwise only the unassigned stock of the product does. It fails with
+// ErrInsufficientStock when either would go below zero.
+func (r *stockMovementRepository) ApplyStockMovement(ctx context.Context, movement models.StockMovement) (models.StockMovement, error) {
+	return r.applyStockMovement(ctx, movement, nil)
+}
+
+// SetStockLevel is ApplyStockMovement with the quantity that brings the
+// stock of the product to stock. The quantity is computed from the locked
+// product row, so stock taken by a concurrent checkout is not written over.
+// It returns a zero movement when the stock already is stock.
+func (r *stockMovementRepository) SetStockLevel(ctx context.Context, movement models.StockMovement, stock int) (models.StockMovement, error) {
+	return r.applyStockMovement(ctx, movement, &stock)
+}
+
+func (r *stockMovementRepository) applyStockMovement(ctx context.Context, movement models.StockMovement, stockLevel *int) (models.StockMovement, error) {
+	applied := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", movement.ProductID).First(&product).Error
+		if err != nil {
+			return err
+		}
+
+		if stockLevel != nil {
+			movement.Quantity = *stockLevel - product.Stock
+			if movement.Quantity == 0 {
+				return nil
+			}
+		}
+
+		movement.StockBefore = product.Stock
+		movement.StockAfter = product.Stock + movement.Quantity
+		if movement.StockAfter < 0 {
+			return ErrInsufficientStock
+		}
+
+		if movement.WarehouseID != nil {
+			err = applyWarehouseStock(tx, *movement.WarehouseID, movement.ProductID, movement.Quantity)
+		} else {
+			err = checkUnassignedStock(tx, product, movement.Quantity)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&product).Update("stock", movement.StockAfter).Error
+		if err != nil {
+			return err
+		}
+
+		applied = true
+		return tx.Create(&movement).Error
+	})
+	if err == nil && !applied {
+		return models.StockMovement{}, nil
+	}
+	return movement, err
+}
+
+func (r *stockMovementRepository) GetStockDiscrepancies(ctx context.Context) ([]StockDiscrepancy, error) {
+	var discrepancies []StockDiscrepancy
+	err := conn(ctx, r.db).Raw(`
+		SELECT * FROM (
+			SELECT
+				p.id AS product_id,
+				p.name AS product_name,
+				p.stock AS stock,
+				COALESCE((
+					SELECT m.stock_after FROM stock_movements m
+					WHERE m.product_id = p.id AND m.deleted_at IS NULL
+					ORDER BY m.id DESC LIMIT 1
+				), 0) AS ledger_stock,
+				COALESCE((
+					SELECT SUM(ws.stock) FROM warehouse_stocks ws
+					WHERE ws.product_id = p.id AND ws.deleted_at IS NULL
+				), 0) AS assigned_stock
+			FROM products p
+			WHERE p.deleted_at IS NULL
+		) s
+		WHERE stock <> ledger_stock OR assigned_stock > stock
+		ORDER BY product_id`).Scan(&discrepancies).Error
+	return discrepancies, err
+}
+
+// ReconcileStockLedger appends an adjustment movement when the stock of the
+// product was changed without going through the ledger, so the ledger ends
+// at the current stock again. The stock itself is not changed. It returns a
+// zero movement when the ledger already matches.
+func (r *stockMovementRepository) ReconcileStockLedger(ctx context.Context, productID uint, note string) (models.StockMovement, error) {
+	var movement models.StockMovement
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", productID).First(&product).Error
+		if err != nil {
+			return err
+		}
+
+		var last models.StockMovement
+		err = tx.Where("product_id = ?", productID).Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+		if last.StockAfter == product.Stock {
+			return nil
+		}
+
+		movement = models.StockMovement{
+			ProductID:   productID,
+			Reason:      models.StockMovementReasonAdjustment,
+			Quantity:    product.Stock - last.StockAfter,
+			StockBefore: last.StockAfter,
+			StockAfter:  product.Stock,
+			Note:        note,
+		}
+		return tx.Create(&movement).Error
+	})
+	return movement, err
+}
+
+// GetOrderHeldStock reads from the ledger the stock the order still holds:
+// what its sales took minus what its cancellations and refunds returned.
+func (r *stockMovementRepository) GetOrderHeldStock(ctx context.Context, orderID uint) ([]HeldStock, error) {
+	var held []HeldStock
+	err := conn(ctx, r.db).Model(&models.StockMovement{}).
+		Select("product_id, warehouse_id, -SUM(quantity) AS quantity").
+		Where("order_id = ? AND reason IN ?", orderID, []string{
+			models.StockMovementReasonSale,
+			models.StockMovementReasonCancellation,
+			models.StockMovementReasonRefund,
+		}).
+		Group("product_id, warehouse_id").
+		Having("SUM(quantity) < 0").
+		Order("product_id, warehouse_id").
+		Scan(&held).Error
+	return held, err
+}
+
+// HasPurchasedProduct reports whether the ledger has a sale of the product to
+// the user in an order that is in one of statuses. Sales are only recorded
+// by checkout, so order details written by hand do not count.
+func (r *stockMovementRepository) HasPurchasedProduct(ctx context.Context, userID, productID uint, statuses []string) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.StockMovement{}).
+		Joins("JOIN orders ON orders.id = stock_movements.order_id AND orders.deleted_at IS NULL").
+		Where("stock_movements.reason = ? AND stock_movements.user_id = ? AND stock_movements.product_id = ? AND orders.status IN ?",
+			models.StockMovementReasonSale, userID, productID, statuses).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// applyWarehouseStock adds quantity to the stock of the product in the
+// warehouse, creating the stock row on the first restock.
+func applyWarehouseStock(tx *gorm.DB, warehouseID, productID uint, quantity int) error {
+	var stock models.WarehouseStock
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND product_id = ?", warehouseID, productID).
+		First(&stock).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if stock.Stock+quantity < 0 {
+		return ErrInsufficientStock
+	}
+	if stock.ID == 0 {
+		if err := tx.Where("id = ?", warehouseID).First(&models.Warehouse{}).Error; err != nil {
+			return err
+		}
+		stock = models.WarehouseStock{WarehouseID: warehouseID, ProductID: productID, Stock: quantity}
+		return tx.Create(&stock).Error
+	}
+	return tx.Model(&stock).Update("stock", stock.Stock+quantity).Error
+}
+
+// checkUnassignedStock makes sure a movement without a warehouse does not
+// take stock that is assigned to a warehouse.
+func checkUnassignedStock(tx *gorm.DB, product models.Product, quantity int) error {
+	if quantity >= 0 {
+		return nil
+	}
+
+	var assigned int64
+	err := tx.Model(&models.WarehouseStock{}).
+		Where("product_id = ?", product.ID).
+		Select("COALESCE(SUM(stock), 0)").
+		Scan(&assigned).Error
+	if err != nil {
+		return err
+	}
+
+	if product.Stock-int(assigned)+quantity < 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type (
+	txKey          struct{}
+	afterCommitKey struct{}
+)
+
+// afterCommit collects the functions to run once a transaction commits.
+type afterCommit struct {
+	fns []func(ctx context.Context)
+}
+
+// Transactor runs a function in a database transaction. Repositories called
+// with the context passed to fn run their queries in that transaction, so a
+// usecase can change several repositories at once. The transaction is rolled
+// back when fn returns an error.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db}
+}
+
+// Transaction nests in the transaction of ctx, if there is one, with a
+// savepoint. The functions passed to AfterCommit in a nested transaction run
+// when the outermost one commits.
+func (t *transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	hooks := &afterCommit{}
+	err := conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		txCtx := context.WithValue(ctx, txKey{}, tx)
+		return fn(context.WithValue(txCtx, afterCommitKey{}, hooks))
+	})
+	if err != nil {
+		return err
+	}
+
+	if parent, ok := ctx.Value(afterCommitKey{}).(*afterCommit); ok {
+		parent.fns = append(parent.fns, hooks.fns...)
+		return nil
+	}
+	for _, fn := range hooks.fns {
+		fn(ctx)
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction of ctx has committed, or right
+// away outside of one. Nothing runs when the transaction rolls back. Use it
+// for side effects, such as mails and webhooks, that must neither hold the
+// transaction open nor go out for changes that are rolled back. fn gets a
+// context outside of the transaction.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommit); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// conn returns the transaction started by Transactor in ctx, or db outside
+// of one, bound to ctx.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	EnableTwoFactor(ctx context.Context, user models.User, codes []models.RecoveryCode) error
+	DisableTwoFactor(ctx context.Context, user models.User) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codes []models.RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	UseTwoFactorStep(ctx context.Context, user models.User, step int64) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db}
+}
+
+// EnableTwoFactor turns 2FA on for the user and stores its first recovery
+// codes in one transaction.
+func (r *twoFactorRepository) EnableTwoFactor(ctx context.Context, user models.User, codes []models.RecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled_at": user.TwoFactorEnabledAt,
+			"two_factor_last_step":  user.TwoFactorLastStep,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, user.ID, codes)
+	})
+}
+
+// DisableTwoFactor turns 2FA off and drops the secret and recovery codes.
+func (r *twoFactorRepository) DisableTwoFactor(ctx context.Context, user models.User) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_secret":     "",
+			"two_factor_enabled_at": nil,
+			"two_factor_last_step":  0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codes []models.RecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseRecoveryCode marks the unused code as used. It reports false when the
+// code does not exist or was used already.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseTwoFactorStep records step as the last accepted TOTP step. It reports
+// false when a code of the same or a later step was accepted before, which
+// stops a code from being replayed.
+func (r *twoFactorRepository) UseTwoFactorStep(ctx context.Context, user models.User, step int64) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+		Update("two_factor_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codes []models.RecoveryCode) error {
+	err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&codes).Error
+}
@@ -1,16 +1,35 @@
 package repositories
 
 import (
+	"context"
+	"fmt"
 	"synapsis-backend/models"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	UserGetById(id uint) (models.User, error)
-	UserGetByEmail(email string) (models.User, error)
-	UserCreate(user models.User) (models.User, error)
-	UserUpdate(user models.User) (models.User, error)
+	UserGetById(ctx context.Context, id uint) (models.User, error)
+	UserGetByEmail(ctx context.Context, email string) (models.User, error)
+	UserCreate(ctx context.Context, user models.User) (models.User, error)
+	UserUpdate(ctx context.Context, user models.User) (models.User, error)
+	UpdateLoginFailures(ctx context.Context, user models.User) error
+	UserGetAll(ctx context.Context, page, limit int, filter UserFilter) ([]models.User, int, error)
+	UserGetExportData(ctx context.Context, id uint) (models.User, error)
+	UserAnonymize(ctx context.Context, user models.User) error
+}
+
+// UserFilter narrows UserGetAll. Zero values match every user.
+type UserFilter struct {
+	// Search matches part of the full name, email or phone number.
+	Search string
+	Role   string
+	// Suspended filters on suspension when set.
+	Suspended *bool
+	// Users registered in [RegisteredFrom, RegisteredTo).
+	RegisteredFrom *time.Time
+	RegisteredTo   *time.Time
 }
 
 type userRepository struct {
@@ -21,24 +40,149 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db}
 }
 
-func (r *userRepository) UserGetById(id uint) (models.User, error) {
+func (r *userRepository) UserGetById(ctx context.Context, id uint) (models.User, error) {
 	var user models.User
-	err := r.db.Where("id = ?", id).First(&user).Error
+	err := conn(ctx, r.db).Where("id = ?", id).First(&user).Error
 	return user, err
 }
 
-func (r *userRepository) UserGetByEmail(email string) (models.User, error) {
+func (r *userRepository) UserGetByEmail(ctx context.Context, email string) (models.User, error) {
 	var user models.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
 	return user, err
 }
 
-func (r *userRepository) UserCreate(user models.User) (models.User, error) {
-	err := r.db.Create(&user).Error
+func (r *userRepository) UserCreate(ctx context.Context, user models.User) (models.User, error) {
+	err := conn(ctx, r.db).Create(&user).Error
 	return user, err
 }
 
-func (r *userRepository) UserUpdate(user models.User) (models.User, error) {
-	err := r.db.Save(&user).Error
+func (r *userRepository) UserUpdate(ctx context.Context, user models.User) (models.User, error) {
+	err := conn(ctx, r.db).Save(&user).Error
 	return user, err
 }
+
+// UpdateLoginFailures saves only FailedLogins and LockedUntil. Logins use it
+// instead of UserUpdate, so they do not write back columns, such as the last
+// accepted TOTP step, that changed after the user was read.
+func (r *userRepository) UpdateLoginFailures(ctx context.Context, user models.User) error {
+	return conn(ctx, r.db).Model(&user).Updates(map[string]interface{}{
+		"failed_logins": user.FailedLogins,
+		"locked_until":  user.LockedUntil,
+	}).Error
+}
+
+func (r *userRepository) UserGetAll(ctx context.Context, page, limit int, filter UserFilter) ([]models.User, int, error) {
+	var (
+		users []models.User
+		count int64
+	)
+	offset := (page - 1) * limit
+
+	query := conn(ctx, r.db).Model(&models.User{})
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		query = query.Where("full_name ILIKE ? OR email ILIKE ? OR phone_number ILIKE ?", search, search, search)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			query = query.Where("suspended_at IS NOT NULL")
+		} else {
+			query = query.Where("suspended_at IS NULL")
+		}
+	}
+	if filter.RegisteredFrom != nil {
+		query = query.Where("created_at >= ?", *filter.RegisteredFrom)
+	}
+	if filter.RegisteredTo != nil {
+		query = query.Where("created_at < ?", *filter.RegisteredTo)
+	}
+
+	err := query.Count(&count).Error
+	if err != nil {
+		return users, int(count), err
+	}
+
+	err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&users).Error
+	return users, int(count), err
+}
+
+// UserGetExportData returns the user with everything a data export needs.
+func (r *userRepository) UserGetExportData(ctx context.Context, id uint) (models.User, error) {
+	var user models.User
+	err := conn(ctx, r.db).
+		Preload("Carts").
+		Preload("Orders.OrderDetail").
+		Preload("Payments").
+		Preload("Reviews").
+		Where("id = ?", id).
+		First(&user).Error
+	return user, err
+}
+
+// UserAnonymize removes the personal data of the user and soft deletes the
+// account in one transaction. Orders and payments are kept for accounting,
+// stripped of the shipping address, and stay linked to the anonymised row.
+func (r *userRepository) UserAnonymize(ctx context.Context, user models.User) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Order{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"shipping_address":   "",
+				"shipping_latitude":  nil,
+				"shipping_longitude": nil,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		personalData := []interface{}{
+			&models.Cart{},
+			&models.Wishlist{},
+			&models.Review{},
+			&models.EmailVerification{},
+			&models.PasswordReset{},
+			&models.RecoveryCode{},
+			&models.UserIdentity{},
+		}
+		for _, model := range personalData {
+			err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Where("email = ?", user.Email).Delete(&models.LoginAttempt{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.LockoutEvent{}).
+			Where("user_id = ?", user.ID).
+			Updates(map[string]interface{}{"email": "", "ip": ""}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&user).Updates(map[string]interface{}{
+			"full_name":             "Deleted User",
+			"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+			"password":              "",
+			"phone_number":          "",
+			"gender":                "",
+			"birth_date":            nil,
+			"citizen":               "",
+			"email_verified_at":     nil,
+			"token_version":         user.TokenVersion + 1,
+			"two_factor_secret":     "",
+			"two_factor_enabled_at": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&user).Error
+	})
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	GetUserIdentity(ctx context.Context, provider, subject string) (models.UserIdentity, error)
+	CreateUserIdentity(ctx context.Context, identity models.UserIdentity) (models.UserIdentity, error)
+	CreateUserWithIdentity(ctx context.Context, user models.User, identity models.UserIdentity) (models.User, error)
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db}
+}
+
+func (r *userIdentityRepository) GetUserIdentity(ctx context.Context, provider, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := conn(ctx, r.db).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	return identity, err
+}
+
+func (r *userIdentityRepository) CreateUserIdentity(ctx context.Context, identity models.UserIdentity) (models.UserIdentity, error) {
+	err := conn(ctx, r.db).Create(&identity).Error
+	return identity, err
+}
+
+// CreateUserWithIdentity creates the user and links the identity to it in
+// one transaction.
+func (r *userIdentityRepository) CreateUserWithIdentity(ctx context.Context, user models.User, identity models.UserIdentity) (models.User, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(&identity).Error
+	})
+	return user, err
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WarehouseRepository interface {
+	GetAllWarehouses(ctx context.Context, page, limit int) ([]models.Warehouse, int, error)
+	GetWarehouseByID(ctx context.Context, id uint) (models.Warehouse, error)
+	CreateWarehouse(ctx context.Context, warehouse models.Warehouse) (models.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse models.Warehouse) (models.Warehouse, error)
+	DeleteWarehouse(ctx context.Context, warehouse models.Warehouse) error
+	GetStocksByWarehouseID(ctx context.Context, warehouseID uint) ([]models.WarehouseStock, error)
+	GetStocksByProductID(ctx context.Context, productID uint) ([]models.WarehouseStock, error)
+	GetAllStockTransfers(ctx context.Context, page, limit int, productID uint) ([]models.StockTransfer, int, error)
+	TransferStock(ctx context.Context, transfer models.StockTransfer) (models.StockTransfer, error)
+}
+
+type warehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) WarehouseRepository {
+	return &warehouseRepository{db}
+}
+
+func (r *warehouseRepository) GetAllWarehouses(ctx context.Context, page, limit int) ([]models.Warehouse, int, error) {
+	var (
+		warehouses []models.Warehouse
+		count      int64
+	)
+	offset := (page - 1) * limit
+
+	err := conn(ctx, r.db).Model(&models.Warehouse{}).Count(&count).Error
+	if err != nil {
+		return warehouses, int(count), err
+	}
+
+	err = conn(ctx, r.db).Order("id").Limit(limit).Offset(offset).Find(&warehouses).Error
+	return warehouses, int(count), err
+}
+
+func (r *warehouseRepository) GetWarehouseByID(ctx context.Context, id uint) (models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := conn(ctx, r.db).Where("id = ?", id).First(&warehouse).Error
+	return warehouse, err
+}
+
+func (r *warehouseRepository) CreateWarehouse(ctx context.Context, warehouse models.Warehouse) (models.Warehouse, error) {
+	err := conn(ctx, r.db).Create(&warehouse).Error
+	return warehouse, err
+}
+
+func (r *warehouseRepository) UpdateWarehouse(ctx context.Context, warehouse models.Warehouse) (models.Warehouse, error) {
+	err := conn(ctx, r.db).Save(&warehouse).Error
+	return warehouse, err
+}
+
+func (r *warehouseRepository) DeleteWarehouse(ctx context.Context, warehouse models.Warehouse) error {
+	err := conn(ctx, r.db).Delete(&warehouse).Error
+	return err
+}
+
+func (r *warehouseRepository) GetStocksByWarehouseID(ctx context.Context, warehouseID uint) ([]models.WarehouseStock, error) {
+	var stocks []models.WarehouseStock
+	err := conn(ctx, r.db).Where("warehouse_id = ?", warehouseID).Order("product_id").Find(&stocks).Error
+	return stocks, err
+}
+
+// GetStocksByProductID returns the stock of the product in every warehouse
+// that still exists.
+func (r *warehouseRepository) GetStocksByProductID(ctx context.Context, productID uint) ([]models.WarehouseStock, error) {
+	var stocks []models.WarehouseStock
+	err := conn(ctx, r.db).
+		Joins("JOIN warehouses ON warehouses.id = warehouse_stocks.warehouse_id AND warehouses.deleted_at IS NULL").
+		Where("warehouse_stocks.product_id = ?", productID).
+		Find(&stocks).Error
+	return stocks, err
+}
+
+func (r *warehouseRepository) GetAllStockTransfers(ctx context.Context, page, limit int, productID uint) ([]models.StockTransfer, int, error) {
+	var (
+		transfers []models.StockTransfer
+		count     int64
+	)
+	offset := (page - 1) * limit
+
+	query := conn(ctx, r.db).Model(&models.StockTransfer{})
+	if productID != 0 {
+		query = query.Where("product_id = ?", productID)
+	}
+
+	err := query.Count(&count).Error
+	if err != nil {
+		return transfers, int(count), err
+	}
+
+	err = query.Order("id DESC").Limit(limit).Offset(offset).Find(&transfers).Error
+	return transfers, int(count), err
+}
+
+// TransferStock moves transfer.Quantity of the product between warehouses and
+// records the transfer in one transaction. The total stock of the product
+// does not change.
+func (r *warehouseRepository) TransferStock(ctx context.Context, transfer models.StockTransfer) (models.StockTransfer, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transfer.ProductID).First(&product).Error
+		if err != nil {
+			return err
+		}
+
+		if transfer.FromWarehouseID == 0 {
+			err = checkUnassignedStock(tx, product, -transfer.Quantity)
+		} else {
+			err = applyWarehouseStock(tx, transfer.FromWarehouseID, transfer.ProductID, -transfer.Quantity)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = applyWarehouseStock(tx, transfer.ToWarehouseID, transfer.ProductID, transfer.Quantity)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&transfer).Error
+	})
+	return transfer, err
+}
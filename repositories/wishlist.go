@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"synapsis-backend/models"
+
+	"gorm.io/gorm"
+)
+
+type WishlistRepository interface {
+	GetAllWishlists(ctx context.Context, page, limit int, userID uint) ([]models.Wishlist, int, error)
+	GetWishlistByID(ctx context.Context, id uint) (models.Wishlist, error)
+	GetWishlistByUserAndProduct(ctx context.Context, userID, productID uint) (models.Wishlist, error)
+	GetWishlistsAwaitingStock(ctx context.Context, productID uint) ([]models.Wishlist, error)
+	CreateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error)
+	UpdateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error)
+	DeleteWishlist(ctx context.Context, wishlist models.Wishlist) error
+	MoveToCart(ctx context.Context, wishlist models.Wishlist, cart models.Cart) (models.Cart, error)
+	SaveForLater(ctx context.Context, cart models.Cart, wishlist models.Wishlist) (models.Wishlist, error)
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db}
+}
+
+func (r *wishlistRepository) GetAllWishlists(ctx context.Context, page, limit int, userID uint) ([]models.Wishlist, int, error) {
+	var (
+		wishlists []models.Wishlist
+		count     int64
+	)
+	offset := (page - 1) * limit
+
+	err := conn(ctx, r.db).Model(&models.Wishlist{}).Where("user_id = ?", userID).Count(&count).Error
+	if err != nil {
+		return wishlists, int(count), err
+	}
+
+	err = conn(ctx, r.db).Where("user_id = ?", userID).Order("id").Limit(limit).Offset(offset).Find(&wishlists).Error
+	return wishlists, int(count), err
+}
+
+func (r *wishlistRepository) GetWishlistByID(ctx context.Context, id uint) (models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := conn(ctx, r.db).Where("id = ?", id).First(&wishlist).Error
+	return wishlist, err
+}
+
+func (r *wishlistRepository) GetWishlistByUserAndProduct(ctx context.Context, userID, productID uint) (models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := conn(ctx, r.db).Where("user_id = ? AND product_id = ?", userID, productID).First(&wishlist).Error
+	return wishlist, err
+}
+
+func (r *wishlistRepository) GetWishlistsAwaitingStock(ctx context.Context, productID uint) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := conn(ctx, r.db).Where("product_id = ? AND notify_when_in_stock = ?", productID, true).Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (r *wishlistRepository) CreateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error) {
+	err := conn(ctx, r.db).Create(&wishlist).Error
+	return wishlist, err
+}
+
+func (r *wishlistRepository) UpdateWishlist(ctx context.Context, wishlist models.Wishlist) (models.Wishlist, error) {
+	err := conn(ctx, r.db).Save(&wishlist).Error
+	return wishlist, err
+}
+
+func (r *wishlistRepository) DeleteWishlist(ctx context.Context, wishlist models.Wishlist) error {
+	err := conn(ctx, r.db).Delete(&wishlist).Error
+	return err
+}
+
+func (r *wishlistRepository) MoveToCart(ctx context.Context, wishlist models.Wishlist, cart models.Cart) (models.Cart, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&cart).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&wishlist).Error
+	})
+	return cart, err
+}
+
+func (r *wishlistRepository) SaveForLater(ctx context.Context, cart models.Cart, wishlist models.Wishlist) (models.Wishlist, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&wishlist).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&cart).Error
+	})
+	return wishlist, err
+}
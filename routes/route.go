@@ -1,13 +1,18 @@
 package routes
 
 import (
-	"log"
+	"log/slog"
+	"synapsis-backend/configs"
 	"synapsis-backend/controllers"
+	"synapsis-backend/mailers"
 	"synapsis-backend/middlewares"
+	"synapsis-backend/models"
+	"synapsis-backend/notifiers"
+	"synapsis-backend/oidc"
 	"synapsis-backend/repositories"
 	"synapsis-backend/usecases"
+	"synapsis-backend/workers"
 
-	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"gorm.io/gorm"
@@ -18,28 +23,80 @@ func init() {
 	middleware.ErrJWTMissing.Message = "Unauthorized"
 }
 
-func Init(e *echo.Echo, db *gorm.DB) {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+// Init registers the routes and adds the background workers of the
+// application to backgroundWorkers. The caller runs them and stops them on
+// shutdown.
+func Init(e *echo.Echo, db *gorm.DB, cfg *configs.Config, log *slog.Logger, backgroundWorkers *workers.Group) error {
+	if err := middlewares.LoadSigningKeys(cfg.JWT); err != nil {
+		return err
+	}
+	e.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
+	notifier := notifiers.NewLogNotifier(log)
+
+	// Mails are written to MAIL_DIR when set, otherwise to the log.
+	mailer := mailers.NewLogMailer(log)
+	if cfg.MailDir != "" {
+		mailer = mailers.NewFileMailer(cfg.MailDir)
+	}
+
+	// Low-stock alerts go to the staff webhook when one is configured.
+	alertNotifier := notifier
+	if cfg.Inventory.LowStockWebhookURL != "" {
+		alertNotifier = notifiers.NewWebhookNotifier(cfg.Inventory.LowStockWebhookURL, cfg.Log.RequestIDHeader)
 	}
 
+	transactor := repositories.NewTransactor(db)
+
 	// USER
 
 	userRepository := repositories.NewUserRepository(db)
-	userUsecase := usecases.NewUserUsecase(userRepository)
+	apiKeyRepository := repositories.NewAPIKeyRepository(db)
+	authMiddleware := middlewares.NewAuthMiddleware(userRepository, apiKeyRepository, log)
+	emailVerificationRepository := repositories.NewEmailVerificationRepository(db)
+	passwordResetRepository := repositories.NewPasswordResetRepository(db)
+	loginAttemptRepository := repositories.NewLoginAttemptRepository(db)
+	twoFactorRepository := repositories.NewTwoFactorRepository(db)
+	userIdentityRepository := repositories.NewUserIdentityRepository(db)
+	oidcProviders := oidc.NewProviders(cfg.OIDC)
+	userUsecase := usecases.NewUserUsecase(
+		userRepository,
+		emailVerificationRepository,
+		passwordResetRepository,
+		loginAttemptRepository,
+		twoFactorRepository,
+		userIdentityRepository,
+		mailer,
+		oidcProviders,
+		cfg.App,
+		log,
+	)
 	userController := controllers.NewUserController(userUsecase)
 
 	api := e.Group("/api/v1")
 	api.POST("/login", userController.UserLogin)
+	api.POST("/login/2fa", userController.VerifyTwoFactorLogin)
+	api.GET("/auth/:provider", userController.OIDCLogin)
+	api.GET("/auth/:provider/callback", userController.OIDCCallback)
 	api.POST("/register", userController.UserRegister)
+	api.GET("/verify-email", userController.VerifyEmail)
+	api.POST("/verify-email", userController.VerifyEmail)
+	api.POST("/forgot-password", userController.ForgotPassword)
+	api.POST("/reset-password", userController.ResetPassword)
 
 	user := api.Group("/user")
-	user.Use(middlewares.JWTMiddleware)
+	user.Use(authMiddleware.JWT)
 	user.Any("", userController.UserCredential)
+	user.DELETE("", userController.DeleteUser)
+	user.GET("/export", userController.ExportUserData)
 	user.PATCH("/update-information", userController.UserUpdateInformation)
 	user.PUT("/update-password", userController.UserUpdatePassword)
 	user.PUT("/update-profile", userController.UserUpdateProfile)
+	user.POST("/resend-verification", userController.ResendVerificationEmail)
+	user.POST("/2fa/setup", userController.SetupTwoFactor)
+	user.POST("/2fa/enable", userController.EnableTwoFactor)
+	user.POST("/2fa/disable", userController.DisableTwoFactor)
+	user.POST("/2fa/recovery-codes", userController.RegenerateRecoveryCodes)
 
 	// Category
 	categoryRepository := repositories.NewCategoryRepository(db)
@@ -47,7 +104,7 @@ func Init(e *echo.Echo, db *gorm.DB) {
 	categoryController := controllers.NewCategoryController(categoryUsecase)
 
 	category := api.Group("/category")
-	category.Use(middlewares.JWTMiddleware)
+	category.Use(authMiddleware.JWT)
 	category.GET("", categoryController.GetAllCategorys)
 	category.GET("/:id", categoryController.GetCategoryByID)
 	category.POST("", categoryController.CreateCategory)
@@ -59,64 +116,153 @@ func Init(e *echo.Echo, db *gorm.DB) {
 	orderDetailController := controllers.NewOrderDetailController(orderDetailUsecase)
 
 	orderDetail := api.Group("/order_detail")
-	orderDetail.Use(middlewares.JWTMiddleware)
+	orderDetail.Use(authMiddleware.JWT)
 	orderDetail.GET("", orderDetailController.GetAllOrderDetails)
 	orderDetail.GET("/:id", orderDetailController.GetOrderDetailByID)
-	orderDetail.POST("", orderDetailController.CreateOrderDetail)
-	orderDetail.PUT("/:id", orderDetailController.UpdateOrderDetail)
-	orderDetail.DELETE("/:id", orderDetailController.DeleteOrderDetail)
+	// Order details are written by checkout, only admins change them by hand.
+	orderDetail.POST("", orderDetailController.CreateOrderDetail, middlewares.RoleMiddleware(models.RoleAdmin))
+	orderDetail.PUT("/:id", orderDetailController.UpdateOrderDetail, middlewares.RoleMiddleware(models.RoleAdmin))
+	orderDetail.DELETE("/:id", orderDetailController.DeleteOrderDetail, middlewares.RoleMiddleware(models.RoleAdmin))
 
 	// Product
 	productRepository := repositories.NewProductRepository(db)
-	productUsecase := usecases.NewProductUsecase(productRepository)
+	wishlistRepository := repositories.NewWishlistRepository(db)
+	reviewRepository := repositories.NewReviewRepository(db)
+	stockMovementRepository := repositories.NewStockMovementRepository(db)
+	stockAlertRepository := repositories.NewStockAlertRepository(db)
+	inventoryUsecase := usecases.NewInventoryUsecase(stockMovementRepository, stockAlertRepository, productRepository, wishlistRepository, notifier, alertNotifier, log)
+	inventoryController := controllers.NewInventoryController(inventoryUsecase)
+	productUsecase := usecases.NewProductUsecase(productRepository, reviewRepository, transactor, inventoryUsecase)
 	productController := controllers.NewProductController(productUsecase)
 
 	product := api.Group("/product")
-	product.Use(middlewares.JWTMiddleware)
-	product.GET("", productController.GetAllProducts)
-	product.GET("/:id", productController.GetProductByID)
-	product.POST("", productController.CreateProduct)
-	product.PUT("/:id", productController.UpdateProduct)
-	product.DELETE("/:id", productController.DeleteProduct)
+	// Product and order routes also take API keys, so the auth middleware
+	// is set per route with the scope the route needs.
+	productsRead := authMiddleware.JWTOrAPIKey(models.ScopeProductsRead)
+	productsWrite := authMiddleware.JWTOrAPIKey(models.ScopeProductsWrite)
+	product.GET("", productController.GetAllProducts, productsRead)
+	product.GET("/:id", productController.GetProductByID, productsRead)
+	product.POST("", productController.CreateProduct, productsWrite)
+	product.PUT("/:id", productController.UpdateProduct, productsWrite)
+	product.DELETE("/:id", productController.DeleteProduct, productsWrite)
+	product.GET("/:id/stock-history", inventoryController.GetStockHistory, authMiddleware.JWT, middlewares.RoleMiddleware(models.RoleAdmin))
+
+	// Review
+	reviewUsecase := usecases.NewReviewUsecase(reviewRepository, productRepository, stockMovementRepository)
+	reviewController := controllers.NewReviewController(reviewUsecase)
+
+	product.GET("/:id/reviews", reviewController.GetProductReviews, authMiddleware.JWT)
+	product.POST("/:id/reviews", reviewController.CreateReview, authMiddleware.JWT)
+	product.PUT("/:id/reviews", reviewController.UpdateReview, authMiddleware.JWT)
 
 	// Cart
 	cartRepository := repositories.NewCartRepository(db)
-	cartUsecase := usecases.NewCartUsecase(cartRepository)
+	cartUsecase := usecases.NewCartUsecase(cartRepository, productRepository, transactor)
 	cartController := controllers.NewCartController(cartUsecase)
 
 	cart := api.Group("/cart")
-	cart.Use(middlewares.JWTMiddleware)
+	cart.Use(authMiddleware.JWT)
 	cart.GET("", cartController.GetAllCarts)
+	cart.GET("/summary", cartController.GetCartSummary)
 	cart.GET("/:id", cartController.GetCartByID)
 	cart.POST("", cartController.CreateCart)
+	cart.POST("/batch", cartController.AddCartItems)
+	cart.PUT("", cartController.ReplaceCart)
+	cart.DELETE("", cartController.ClearCart)
 	cart.PUT("/:id", cartController.UpdateCart)
 	cart.DELETE("/:id", cartController.DeleteCart)
 
+	// Wishlist
+	wishlistUsecase := usecases.NewWishlistUsecase(wishlistRepository, cartRepository, productRepository)
+	wishlistController := controllers.NewWishlistController(wishlistUsecase)
+
+	wishlist := api.Group("/wishlist")
+	wishlist.Use(authMiddleware.JWT)
+	wishlist.GET("", wishlistController.GetAllWishlists)
+	wishlist.GET("/:id", wishlistController.GetWishlistByID)
+	wishlist.POST("", wishlistController.CreateWishlist)
+	wishlist.PUT("/:id", wishlistController.UpdateWishlist)
+	wishlist.DELETE("/:id", wishlistController.DeleteWishlist)
+	wishlist.POST("/:id/move-to-cart", wishlistController.MoveToCart)
+	cart.POST("/:id/save-for-later", wishlistController.SaveForLater)
+
 	// Order
 	orderRepository := repositories.NewOrderRepository(db)
-	orderUsecase := usecases.NewOrderUsecase(orderRepository, cartRepository, productRepository, orderDetailRepository)
+	warehouseRepository := repositories.NewWarehouseRepository(db)
+	allocationStrategy, err := usecases.NewAllocationStrategy(cfg.Inventory.AllocationStrategy)
+	if err != nil {
+		return err
+	}
+	orderUsecase := usecases.NewOrderUsecase(orderRepository, cartRepository, productRepository, orderDetailRepository, warehouseRepository, userRepository, transactor, inventoryUsecase, allocationStrategy, log)
 	orderController := controllers.NewOrderController(orderUsecase)
 
 	order := api.Group("/order")
-	order.Use(middlewares.JWTMiddleware)
+	ordersRead := authMiddleware.JWTOrAPIKey(models.ScopeOrdersRead)
+	ordersWrite := authMiddleware.JWTOrAPIKey(models.ScopeOrdersWrite)
 	cart.POST("/checkout", orderController.Checkout)
-	order.GET("", orderController.GetAllOrders)
-	order.GET("/:id", orderController.GetOrderByID)
-	order.POST("", orderController.CreateOrder)
-	order.PUT("/:id", orderController.UpdateOrder)
-	order.DELETE("/:id", orderController.DeleteOrder)
+	order.GET("", orderController.GetAllOrders, ordersRead)
+	order.GET("/:id", orderController.GetOrderByID, ordersRead)
+	// Customers order through checkout, writing orders by hand is left to
+	// admins and API keys.
+	order.POST("", orderController.CreateOrder, ordersWrite, middlewares.RoleMiddleware(models.RoleAdmin))
+	order.PUT("/:id", orderController.UpdateOrder, ordersWrite, middlewares.RoleMiddleware(models.RoleAdmin))
+	order.DELETE("/:id", orderController.DeleteOrder, ordersWrite, middlewares.RoleMiddleware(models.RoleAdmin))
 
 	// Payment
 	paymentRepository := repositories.NewPaymentRepository(db)
-	paymentUsecase := usecases.NewPaymentUsecase(paymentRepository, orderRepository)
+	paymentUsecase := usecases.NewPaymentUsecase(paymentRepository, orderRepository, userRepository, transactor, inventoryUsecase, log)
 	paymentController := controllers.NewPaymentController(paymentUsecase)
 
 	payment := api.Group("/payment")
-	payment.Use(middlewares.JWTMiddleware)
+	payment.Use(authMiddleware.JWT)
 	payment.GET("", paymentController.GetAllPayments)
 	payment.GET("/:id", paymentController.GetPaymentByID)
 	payment.POST("", paymentController.CreatePayment)
-	payment.PUT("/:id", paymentController.UpdatePayment)
-	payment.DELETE("/:id", paymentController.DeletePayment)
+	payment.PUT("/:id", paymentController.UpdatePayment, middlewares.RoleMiddleware(models.RoleAdmin))
+	payment.DELETE("/:id", paymentController.DeletePayment, middlewares.RoleMiddleware(models.RoleAdmin))
+
+	// Admin
+	admin := api.Group("/admin")
+	admin.Use(authMiddleware.JWT, middlewares.RoleMiddleware(models.RoleAdmin))
+	admin.GET("/reviews", reviewController.GetAllReviews)
+	admin.PUT("/reviews/:id/hide", reviewController.HideReview)
+	admin.PUT("/reviews/:id/unhide", reviewController.UnhideReview)
+	admin.POST("/product/:id/stock-adjustments", inventoryController.AdjustStock)
+	admin.GET("/inventory/alerts", inventoryController.GetStockAlerts)
+	admin.GET("/lockouts", userController.GetLockoutEvents)
+
+	// API keys
+	apiKeyUsecase := usecases.NewAPIKeyUsecase(apiKeyRepository)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyUsecase)
+
+	admin.GET("/api-keys", apiKeyController.GetAllAPIKeys)
+	admin.POST("/api-keys", apiKeyController.CreateAPIKey)
+	admin.DELETE("/api-keys/:id", apiKeyController.RevokeAPIKey)
+	admin.POST("/users/:id/unlock", userController.UnlockUser)
+
+	// User management
+	admin.GET("/users", userController.GetAllUsers)
+	admin.GET("/users/:id", userController.GetUserByID)
+	admin.GET("/users/:id/orders", orderController.GetUserOrders)
+	admin.GET("/users/:id/payments", paymentController.GetUserPayments)
+	admin.GET("/users/:id/cart", cartController.GetUserCart)
+	admin.POST("/users/:id/suspend", userController.SuspendUser)
+	admin.POST("/users/:id/reactivate", userController.ReactivateUser)
+	admin.POST("/users/:id/force-password-reset", userController.ForcePasswordReset)
+
+	// Warehouse
+	warehouseUsecase := usecases.NewWarehouseUsecase(warehouseRepository, productRepository)
+	warehouseController := controllers.NewWarehouseController(warehouseUsecase)
+
+	admin.GET("/warehouses", warehouseController.GetAllWarehouses)
+	admin.GET("/warehouses/:id", warehouseController.GetWarehouseByID)
+	admin.POST("/warehouses", warehouseController.CreateWarehouse)
+	admin.PUT("/warehouses/:id", warehouseController.UpdateWarehouse)
+	admin.DELETE("/warehouses/:id", warehouseController.DeleteWarehouse)
+	admin.GET("/warehouses/:id/stocks", warehouseController.GetWarehouseStocks)
+	admin.GET("/stock-transfers", warehouseController.GetAllStockTransfers)
+	admin.POST("/stock-transfers", warehouseController.TransferStock)
 
+	backgroundWorkers.Add(workers.NewLowStockMonitor(inventoryUsecase, cfg.Inventory.LowStockCheckInterval, log))
+	return nil
 }
@@ -0,0 +1,246 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/models"
+	"synapsis-backend/repositories"
+	"time"
+)
+
+// GetAllUsers godoc
+// @Summary      Get all users
+// @Description  Get users, newest first. Search matches part of the name, email or phone number. Registration dates are inclusive and use YYYY-MM-DD
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param search query string false "Search by name, email or phone number"
+// @Param role query string false "Filter by role" Enums(user, admin)
+// @Param status query string false "Filter by status" Enums(active, suspended)
+// @Param registered_from query string false "Registered on or after (YYYY-MM-DD)"
+// @Param registered_to query string false "Registered on or before (YYYY-MM-DD)"
+// @Success      200 {object} dtos.GetAllAdminUserStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users [get]
+// @Security BearerAuth
+func (u *userUsecase) GetAllUsers(ctx context.Context, page, limit int, input dtos.UserFilterInput) ([]dtos.AdminUserResponse, int, error) {
+	filter := repositories.UserFilter{
+		Search: input.Search,
+		Role:   input.Role,
+	}
+
+	switch input.Status {
+	case "":
+	case "active", "suspended":
+		suspended := input.Status == "suspended"
+		filter.Suspended = &suspended
+	default:
+		return nil, 0, apperrors.Validation("Status must be active or suspended")
+	}
+
+	if input.RegisteredFrom != "" {
+		from, err := time.Parse("2006-01-02", input.RegisteredFrom)
+		if err != nil {
+			return nil, 0, apperrors.Validation("Invalid registered_from date")
+		}
+		filter.RegisteredFrom = &from
+	}
+	if input.RegisteredTo != "" {
+		to, err := time.Parse("2006-01-02", input.RegisteredTo)
+		if err != nil {
+			return nil, 0, apperrors.Validation("Invalid registered_to date")
+		}
+		// The whole day of registered_to is included.
+		to = to.AddDate(0, 0, 1)
+		filter.RegisteredTo = &to
+	}
+
+	users, count, err := u.userRepo.UserGetAll(ctx, page, limit, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userResponses := []dtos.AdminUserResponse{}
+	for _, user := range users {
+		userResponses = append(userResponses, adminUserResponse(user))
+	}
+
+	return userResponses, count, nil
+}
+
+// GetUserByID godoc
+// @Summary      Get user by ID
+// @Description  Get a user with their account status
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Success      200 {object} dtos.AdminUserStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id} [get]
+// @Security BearerAuth
+func (u *userUsecase) GetUserByID(ctx context.Context, id uint) (dtos.AdminUserResponse, error) {
+	user, err := u.userRepo.UserGetById(ctx, id)
+	if err != nil {
+		return dtos.AdminUserResponse{}, apperrors.NotFound("User not found")
+	}
+	return adminUserResponse(user), nil
+}
+
+// SuspendUser godoc
+// @Summary      Suspend user
+// @Description  Suspend an account. The user is logged out and cannot log in until reactivated
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Param        request body dtos.UserSuspendInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.AdminUserStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id}/suspend [post]
+// @Security BearerAuth
+func (u *userUsecase) SuspendUser(ctx context.Context, adminID, userID uint, input dtos.UserSuspendInput) (dtos.AdminUserResponse, error) {
+	user, err := u.userRepo.UserGetById(ctx, userID)
+	if err != nil {
+		return dtos.AdminUserResponse{}, apperrors.NotFound("User not found")
+	}
+	if user.ID == adminID {
+		return dtos.AdminUserResponse{}, apperrors.Forbidden("You cannot suspend your own account")
+	}
+	if user.SuspendedAt != nil {
+		return dtos.AdminUserResponse{}, apperrors.Conflict("User is already suspended")
+	}
+	if input.Reason == "" {
+		return dtos.AdminUserResponse{}, apperrors.Validation("Reason is required")
+	}
+
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.SuspendedReason = input.Reason
+	user.SuspendedBy = &adminID
+	// Revoke the tokens too, so they stay dead after a reactivation.
+	user.TokenVersion++
+
+	user, err = u.userRepo.UserUpdate(ctx, user)
+	if err != nil {
+		return dtos.AdminUserResponse{}, err
+	}
+	return adminUserResponse(user), nil
+}
+
+// ReactivateUser godoc
+// @Summary      Reactivate user
+// @Description  Lift the suspension of an account
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Success      200 {object} dtos.AdminUserStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id}/reactivate [post]
+// @Security BearerAuth
+func (u *userUsecase) ReactivateUser(ctx context.Context, userID uint) (dtos.AdminUserResponse, error) {
+	user, err := u.userRepo.UserGetById(ctx, userID)
+	if err != nil {
+		return dtos.AdminUserResponse{}, apperrors.NotFound("User not found")
+	}
+	if user.SuspendedAt == nil {
+		return dtos.AdminUserResponse{}, errors.New("User is not suspended")
+	}
+
+	user.SuspendedAt = nil
+	user.SuspendedReason = ""
+	user.SuspendedBy = nil
+
+	user, err = u.userRepo.UserUpdate(ctx, user)
+	if err != nil {
+		return dtos.AdminUserResponse{}, err
+	}
+	return adminUserResponse(user), nil
+}
+
+// ForcePasswordReset godoc
+// @Summary      Force password reset
+// @Description  Replace the password of an account with a random one, log the user out and email them a reset link
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id}/force-password-reset [post]
+// @Security BearerAuth
+func (u *userUsecase) ForcePasswordReset(ctx context.Context, userID uint) error {
+	user, err := u.userRepo.UserGetById(ctx, userID)
+	if err != nil {
+		return apperrors.NotFound("User not found")
+	}
+
+	randomPassword, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	password, err := helpers.HashPassword(randomPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = password
+	user.TokenVersion++
+
+	user, err = u.userRepo.UserUpdate(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	if err := u.sendPasswordReset(ctx, user); err != nil {
+		return fmt.Errorf("Password was reset but the email could not be sent: %w", err)
+	}
+	return nil
+}
+
+func adminUserResponse(user models.User) dtos.AdminUserResponse {
+	return dtos.AdminUserResponse{
+		ID:               user.ID,
+		FullName:         user.FullName,
+		Email:            user.Email,
+		PhoneNumber:      user.PhoneNumber,
+		Role:             user.Role,
+		EmailVerified:    user.EmailVerifiedAt != nil,
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+		LockedUntil:      user.LockedUntil,
+		SuspendedAt:      user.SuspendedAt,
+		SuspendedReason:  user.SuspendedReason,
+		SuspendedBy:      user.SuspendedBy,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"synapsis-backend/models"
+)
+
+const (
+	AllocationStrategyMostStock = "most_stock"
+	AllocationStrategyNearest   = "nearest"
+)
+
+// WarehouseCandidate is a warehouse that has stock of the product being
+// allocated.
+type WarehouseCandidate struct {
+	Warehouse models.Warehouse
+	Stock     int
+}
+
+// AllocationStrategy decides which warehouses an order line is shipped from.
+// Checkout takes the stock from the candidates in the order returned by Rank
+// until the line is covered.
+type AllocationStrategy interface {
+	Rank(candidates []WarehouseCandidate, order models.Order) []WarehouseCandidate
+}
+
+// NewAllocationStrategy returns the strategy called name, defaulting to
+// most_stock when name is empty.
+func NewAllocationStrategy(name string) (AllocationStrategy, error) {
+	switch name {
+	case "", AllocationStrategyMostStock:
+		return mostStockStrategy{}, nil
+	case AllocationStrategyNearest:
+		return nearestStrategy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown warehouse allocation strategy %q", name)
+	}
+}
+
+// mostStockStrategy ships from the warehouses holding the most stock first,
+// which keeps the number of shipments per line low.
+type mostStockStrategy struct{}
+
+func (mostStockStrategy) Rank(candidates []WarehouseCandidate, order models.Order) []WarehouseCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Stock > candidates[j].Stock
+	})
+	return candidates
+}
+
+// nearestStrategy ships from the warehouses closest to the shipping address.
+// Orders without shipping coordinates fall back to most_stock.
+type nearestStrategy struct{}
+
+func (nearestStrategy) Rank(candidates []WarehouseCandidate, order models.Order) []WarehouseCandidate {
+	if order.ShippingLatitude == nil || order.ShippingLongitude == nil {
+		return mostStockStrategy{}.Rank(candidates, order)
+	}
+
+	distance := func(warehouse models.Warehouse) float64 {
+		return haversineKm(*order.ShippingLatitude, *order.ShippingLongitude, warehouse.Latitude, warehouse.Longitude)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i].Warehouse) < distance(candidates[j].Warehouse)
+	})
+	return candidates
+}
+
+// haversineKm returns the great-circle distance between two coordinates.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,168 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/models"
+	"synapsis-backend/repositories"
+	"time"
+)
+
+type APIKeyUsecase interface {
+	GetAllAPIKeys(ctx context.Context, page, limit int) ([]dtos.APIKeyResponse, int, error)
+	CreateAPIKey(ctx context.Context, adminID uint, input dtos.APIKeyInput) (dtos.APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+}
+
+type apiKeyUsecase struct {
+	apiKeyRepo repositories.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(APIKeyRepo repositories.APIKeyRepository) APIKeyUsecase {
+	return &apiKeyUsecase{APIKeyRepo}
+}
+
+// GetAllAPIKeys godoc
+// @Summary      Get all API keys
+// @Description  Get all API keys, newest first. The keys themselves are never returned again
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success      200 {object} dtos.GetAllAPIKeyStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/api-keys [get]
+// @Security BearerAuth
+func (u *apiKeyUsecase) GetAllAPIKeys(ctx context.Context, page, limit int) ([]dtos.APIKeyResponse, int, error) {
+	apiKeys, count, err := u.apiKeyRepo.GetAllAPIKeys(ctx, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	apiKeyResponses := []dtos.APIKeyResponse{}
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, toAPIKeyResponse(apiKey))
+	}
+
+	return apiKeyResponses, count, nil
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  Create an API key for another system. Send it as the X-API-Key header. The key is only shown in this response
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.APIKeyInput true "Payload Body [RAW]"
+// @Success      201 {object} dtos.APIKeyCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/api-keys [post]
+// @Security BearerAuth
+func (u *apiKeyUsecase) CreateAPIKey(ctx context.Context, adminID uint, input dtos.APIKeyInput) (dtos.APIKeyResponse, error) {
+	var apiKeyResponse dtos.APIKeyResponse
+
+	if input.Name == "" {
+		return apiKeyResponse, apperrors.Validation("Name is required")
+	}
+	if len(input.Scopes) == 0 {
+		return apiKeyResponse, apperrors.Validation("At least one scope is required")
+	}
+	for _, scope := range input.Scopes {
+		if !isAPIKeyScope(scope) {
+			return apiKeyResponse, apperrors.Validation("Unknown scope %q, must be one of %s", scope, strings.Join(models.APIKeyScopes, ", "))
+		}
+	}
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		return apiKeyResponse, apperrors.Validation("Expiry must be in the future")
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return apiKeyResponse, err
+	}
+	key := middlewares.APIKeyPrefix + token
+
+	apiKey, err := u.apiKeyRepo.CreateAPIKey(ctx, models.APIKey{
+		Name:      input.Name,
+		Prefix:    key[:len(middlewares.APIKeyPrefix)+6],
+		KeyHash:   helpers.HashToken(key),
+		Scopes:    strings.Join(input.Scopes, " "),
+		ExpiresAt: input.ExpiresAt,
+		CreatedBy: adminID,
+	})
+	if err != nil {
+		return apiKeyResponse, err
+	}
+
+	apiKeyResponse = toAPIKeyResponse(apiKey)
+	apiKeyResponse.Key = &key
+	return apiKeyResponse, nil
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Revoke an API key, it is refused from then on
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID API key"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/api-keys/{id} [delete]
+// @Security BearerAuth
+func (u *apiKeyUsecase) RevokeAPIKey(ctx context.Context, id uint) error {
+	apiKey, err := u.apiKeyRepo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return apperrors.NotFound("API key not found")
+	}
+	if apiKey.RevokedAt != nil {
+		return apperrors.Conflict("API key is already revoked")
+	}
+
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	_, err = u.apiKeyRepo.UpdateAPIKey(ctx, apiKey)
+	return err
+}
+
+func isAPIKeyScope(scope string) bool {
+	for _, s := range models.APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func toAPIKeyResponse(apiKey models.APIKey) dtos.APIKeyResponse {
+	return dtos.APIKeyResponse{
+		APIKeyID:   apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Fields(apiKey.Scopes),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedBy:  apiKey.CreatedBy,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
@@ -1,27 +1,46 @@
 package usecases
 
 import (
+	"context"
+	"errors"
+	"synapsis-backend/apperrors"
 	"synapsis-backend/dtos"
 	"synapsis-backend/models"
 	"synapsis-backend/repositories"
+
+	"gorm.io/gorm"
 )
 
 type CartUsecase interface {
-	GetAllCarts(page, limit, user_id int) ([]dtos.CartResponse, int, error)
-	GetCartByID(id uint) (dtos.CartResponse, error)
-	CreateCart(cart *dtos.CartInput) (dtos.CartResponse, error)
-	UpdateCart(id uint, cartInput dtos.CartInput) (dtos.CartResponse, error)
-	DeleteCart(id uint) error
+	GetAllCarts(ctx context.Context, page, limit, user_id int) ([]dtos.CartResponse, int, error)
+	GetCartByID(ctx context.Context, id uint) (dtos.CartResponse, error)
+	CreateCart(ctx context.Context, cart *dtos.CartInput) (dtos.CartResponse, error)
+	UpdateCart(ctx context.Context, id uint, cartInput dtos.CartInput) (dtos.CartResponse, error)
+	DeleteCart(ctx context.Context, id uint) error
+	GetCartSummary(ctx context.Context, userID uint) (dtos.CartSummaryResponse, error)
+	GetUserCart(ctx context.Context, userID uint) (dtos.CartSummaryResponse, error)
+	AddCartItems(ctx context.Context, userID uint, input dtos.CartBatchInput) ([]dtos.CartResponse, error)
+	ReplaceCart(ctx context.Context, userID uint, input dtos.CartBatchInput) ([]dtos.CartResponse, error)
+	ClearCart(ctx context.Context, userID uint) error
 }
 
 type cartUsecase struct {
-	cartRepo repositories.CartRepository
+	cartRepo    repositories.CartRepository
+	productRepo repositories.ProductRepository
+	transactor  repositories.Transactor
 }
 
-func NewCartUsecase(CartRepo repositories.CartRepository) CartUsecase {
-	return &cartUsecase{CartRepo}
+func NewCartUsecase(CartRepo repositories.CartRepository, ProductRepo repositories.ProductRepository, Transactor repositories.Transactor) CartUsecase {
+	return &cartUsecase{CartRepo, ProductRepo, Transactor}
 }
 
+const (
+	StockStatusInStock      = "in_stock"
+	StockStatusInsufficient = "insufficient_stock"
+	StockStatusOutOfStock   = "out_of_stock"
+	StockStatusUnavailable  = "unavailable"
+)
+
 // GetAllCarts godoc
 // @Summary      Get all cart
 // @Description  Get all cart
@@ -39,15 +58,15 @@ func NewCartUsecase(CartRepo repositories.CartRepository) CartUsecase {
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /cart [get]
 // @Security BearerAuth
-func (u *cartUsecase) GetAllCarts(page, limit, user_id int) ([]dtos.CartResponse, int, error) {
-	carts, count, err := u.cartRepo.GetAllCarts(page, limit, user_id)
+func (u *cartUsecase) GetAllCarts(ctx context.Context, page, limit, user_id int) ([]dtos.CartResponse, int, error) {
+	carts, count, err := u.cartRepo.GetAllCarts(ctx, page, limit, user_id)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	var cartResponses []dtos.CartResponse
 	for _, cart := range carts {
-		// category, err := u.cartRepo.GetCategoryByID(cart.CategoryID)
+		// category, err := u.cartRepo.GetCategoryByID(ctx, cart.CategoryID)
 
 		cartResponse := dtos.CartResponse{
 			CartID:    cart.ID,
@@ -79,9 +98,9 @@ func (u *cartUsecase) GetAllCarts(page, limit, user_id int) ([]dtos.CartResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /cart/{id} [get]
 // @Security BearerAuth
-func (u *cartUsecase) GetCartByID(id uint) (dtos.CartResponse, error) {
+func (u *cartUsecase) GetCartByID(ctx context.Context, id uint) (dtos.CartResponse, error) {
 	var cartResponses dtos.CartResponse
-	cart, err := u.cartRepo.GetCartByID(id)
+	cart, err := u.cartRepo.GetCartByID(ctx, id)
 	if err != nil {
 		return cartResponses, err
 	}
@@ -106,23 +125,29 @@ func (u *cartUsecase) GetCartByID(id uint) (dtos.CartResponse, error) {
 // @Param        request body dtos.CartInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.CartStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /cart [post]
 // @Security BearerAuth
-func (u *cartUsecase) CreateCart(cart *dtos.CartInput) (dtos.CartResponse, error) {
+func (u *cartUsecase) CreateCart(ctx context.Context, cart *dtos.CartInput) (dtos.CartResponse, error) {
 	var cartResponses dtos.CartResponse
 
+	price, err := u.getLinePrice(ctx, cart.ProductID, cart.Quantity)
+	if err != nil {
+		return cartResponses, err
+	}
+
 	createCart := models.Cart{
 		UserID:    cart.UserID,
 		ProductID: cart.ProductID,
 		Quantity:  cart.Quantity,
-		Price:     cart.Price,
+		Price:     price,
 	}
 
-	createdCart, err := u.cartRepo.CreateCart(createCart)
+	createdCart, err := u.cartRepo.CreateCart(ctx, createCart)
 	if err != nil {
 		return cartResponses, err
 	}
@@ -132,7 +157,7 @@ func (u *cartUsecase) CreateCart(cart *dtos.CartInput) (dtos.CartResponse, error
 		UserID:    cart.UserID,
 		ProductID: cart.ProductID,
 		Quantity:  cart.Quantity,
-		Price:     cart.Price,
+		Price:     createdCart.Price,
 		CreatedAt: createdCart.CreatedAt,
 		UpdatedAt: createdCart.UpdatedAt,
 	}
@@ -150,18 +175,19 @@ func (u *cartUsecase) CreateCart(cart *dtos.CartInput) (dtos.CartResponse, error
 // @Param        request body dtos.CartInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.CartStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /cart/{id} [put]
 // @Security BearerAuth
-func (u *cartUsecase) UpdateCart(id uint, cartInput dtos.CartInput) (dtos.CartResponse, error) {
+func (u *cartUsecase) UpdateCart(ctx context.Context, id uint, cartInput dtos.CartInput) (dtos.CartResponse, error) {
 
 	var cart models.Cart
 	var cartResponse dtos.CartResponse
 
-	cart, err := u.cartRepo.GetCartByID(id)
+	cart, err := u.cartRepo.GetCartByID(ctx, id)
 	if err != nil {
 		return cartResponse, err
 	}
@@ -170,9 +196,12 @@ func (u *cartUsecase) UpdateCart(id uint, cartInput dtos.CartInput) (dtos.CartRe
 	cart.UserID = cartInput.UserID
 	cart.ProductID = cartInput.ProductID
 	cart.Quantity = cartInput.Quantity
-	cart.Price = cartInput.Price
+	cart.Price, err = u.getLinePrice(ctx, cartInput.ProductID, cartInput.Quantity)
+	if err != nil {
+		return cartResponse, err
+	}
 
-	cart, err = u.cartRepo.UpdateCart(cart)
+	cart, err = u.cartRepo.UpdateCart(ctx, cart)
 
 	if err != nil {
 		return cartResponse, err
@@ -205,12 +234,303 @@ func (u *cartUsecase) UpdateCart(id uint, cartInput dtos.CartInput) (dtos.CartRe
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /cart/{id} [delete]
 // @Security BearerAuth
-func (u *cartUsecase) DeleteCart(id uint) error {
-	cart, err := u.cartRepo.GetCartByID(id)
+func (u *cartUsecase) DeleteCart(ctx context.Context, id uint) error {
+	cart, err := u.cartRepo.GetCartByID(ctx, id)
 
 	if err != nil {
-		return nil
+		return err
 	}
-	err = u.cartRepo.DeleteCart(cart)
+	err = u.cartRepo.DeleteCart(ctx, cart)
 	return err
 }
+
+// GetUserCart godoc
+// @Summary      Get cart of a user
+// @Description  Get the cart summary of a user
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Success      200 {object} dtos.CartSummaryStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id}/cart [get]
+// @Security BearerAuth
+func (u *cartUsecase) GetUserCart(ctx context.Context, userID uint) (dtos.CartSummaryResponse, error) {
+	return u.GetCartSummary(ctx, userID)
+}
+
+// GetCartSummary godoc
+// @Summary      Get cart summary
+// @Description  Get the cart of the logged in user with product details, discounts, estimated tax and grand total
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.CartSummaryStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /cart/summary [get]
+// @Security BearerAuth
+func (u *cartUsecase) GetCartSummary(ctx context.Context, userID uint) (dtos.CartSummaryResponse, error) {
+	summary := dtos.CartSummaryResponse{
+		UserID: userID,
+		Items:  []dtos.CartSummaryItemResponse{},
+	}
+
+	carts, err := u.cartRepo.GetCartsByUserID(ctx, userID)
+	if err != nil {
+		return summary, err
+	}
+
+	// Cart.Price is the line total at the price the product had when it was
+	// added. The summary is priced at the current price, like checkout.
+	var lines []linePrice
+	for _, cart := range carts {
+		item := dtos.CartSummaryItemResponse{
+			CartID:    cart.ID,
+			ProductID: cart.ProductID,
+			Quantity:  cart.Quantity,
+		}
+		if cart.Quantity > 0 {
+			item.AddedPrice = cart.Price / cart.Quantity
+		}
+
+		product, err := u.productRepo.GetProductByID(ctx, cart.ProductID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return summary, err
+		}
+
+		// Products that were deleted or deactivated stay visible in the
+		// summary but are not counted towards the totals.
+		if err != nil || !product.Status {
+			item.ProductName = product.Name
+			item.StockStatus = StockStatusUnavailable
+			summary.Items = append(summary.Items, item)
+			continue
+		}
+
+		line := priceLine(product.Price, cart.Quantity)
+		lines = append(lines, line)
+
+		item.ProductName = product.Name
+		item.CurrentPrice = product.Price
+		item.PriceChanged = cart.Price != line.SubTotal
+		item.Stock = product.Stock
+		item.StockStatus = stockStatus(product.Stock, cart.Quantity)
+		item.SubTotal = line.SubTotal
+		item.Discount = line.Discount
+		item.Total = line.Total
+
+		summary.TotalItems += item.Quantity
+		if item.PriceChanged {
+			summary.PriceChanged = true
+		}
+		summary.Items = append(summary.Items, item)
+	}
+
+	total := priceCart(lines)
+	summary.SubTotal = total.SubTotal
+	summary.Discount = total.Discount
+	summary.EstimatedTax = total.Tax
+	summary.GrandTotal = total.GrandTotal
+
+	return summary, nil
+}
+
+func stockStatus(stock, quantity int) string {
+	switch {
+	case stock <= 0:
+		return StockStatusOutOfStock
+	case stock < quantity:
+		return StockStatusInsufficient
+	default:
+		return StockStatusInStock
+	}
+}
+
+// AddCartItems godoc
+// @Summary      Add several products to cart
+// @Description  Add several products to the cart of the logged in user in one transaction. Products already in the cart get their quantity increased
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.CartBatchInput true "Payload Body [RAW]"
+// @Success      201 {object} dtos.CartBatchStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /cart/batch [post]
+// @Security BearerAuth
+func (u *cartUsecase) AddCartItems(ctx context.Context, userID uint, input dtos.CartBatchInput) ([]dtos.CartResponse, error) {
+	if len(input.Items) == 0 {
+		return nil, apperrors.Validation("Items is empty")
+	}
+
+	// The cart is locked while the items are merged into it, so concurrent
+	// adds do not write over each other's quantities.
+	err := u.transactor.Transaction(ctx, func(ctx context.Context) error {
+		existing, err := u.cartRepo.LockCartsByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		lines := make(map[uint]models.Cart, len(existing))
+		for _, cart := range existing {
+			lines[cart.ProductID] = cart
+		}
+
+		carts, err := u.buildCartLines(ctx, userID, lines, input.Items)
+		if err != nil {
+			return err
+		}
+
+		_, err = u.cartRepo.SaveCarts(ctx, carts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.getCartResponses(ctx, userID)
+}
+
+// ReplaceCart godoc
+// @Summary      Replace cart
+// @Description  Replace the whole cart of the logged in user in one transaction. An empty list of items clears the cart
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.CartBatchInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.CartBatchStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /cart [put]
+// @Security BearerAuth
+func (u *cartUsecase) ReplaceCart(ctx context.Context, userID uint, input dtos.CartBatchInput) ([]dtos.CartResponse, error) {
+	carts, err := u.buildCartLines(ctx, userID, map[uint]models.Cart{}, input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.cartRepo.ReplaceCarts(ctx, userID, carts); err != nil {
+		return nil, err
+	}
+
+	return u.getCartResponses(ctx, userID)
+}
+
+// ClearCart godoc
+// @Summary      Clear cart
+// @Description  Delete every product from the cart of the logged in user
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.StatusOKDeletedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /cart [delete]
+// @Security BearerAuth
+func (u *cartUsecase) ClearCart(ctx context.Context, userID uint) error {
+	return u.cartRepo.DeleteCartsByUserID(ctx, userID)
+}
+
+// buildCartLines merges items into lines (keyed by product ID) and checks
+// every resulting line against the product stock. Only the lines touched by
+// items are returned, priced at the current product price.
+func (u *cartUsecase) buildCartLines(ctx context.Context, userID uint, lines map[uint]models.Cart, items []dtos.CartItemInput) ([]models.Cart, error) {
+	var touched []uint
+	seen := map[uint]bool{}
+	for _, item := range items {
+		if item.ProductID == 0 {
+			return nil, apperrors.Validation("Product ID is required")
+		}
+		if item.Quantity <= 0 {
+			return nil, apperrors.Validation("Quantity of product %d must be greater than 0", item.ProductID)
+		}
+
+		cart, ok := lines[item.ProductID]
+		if !ok {
+			cart = models.Cart{UserID: userID, ProductID: item.ProductID}
+		}
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			touched = append(touched, item.ProductID)
+		}
+		cart.Quantity += item.Quantity
+		lines[item.ProductID] = cart
+	}
+
+	carts := make([]models.Cart, 0, len(touched))
+	for _, productID := range touched {
+		cart := lines[productID]
+
+		product, err := u.productRepo.GetProductByID(ctx, productID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperrors.NotFound("Product %d not found", productID)
+			}
+			return nil, err
+		}
+		if !product.Status {
+			return nil, apperrors.Conflict("Product %d is not available", productID)
+		}
+		if product.Stock < cart.Quantity {
+			return nil, apperrors.Conflict("Insufficient stock for product %d: requested %d, available %d", productID, cart.Quantity, product.Stock)
+		}
+
+		cart.Price = product.Price * cart.Quantity
+		carts = append(carts, cart)
+	}
+
+	return carts, nil
+}
+
+// getLinePrice returns the Cart.Price of quantity items of the product.
+func (u *cartUsecase) getLinePrice(ctx context.Context, productID uint, quantity int) (int, error) {
+	product, err := u.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, apperrors.NotFound("Product %d not found", productID)
+		}
+		return 0, err
+	}
+	return product.Price * quantity, nil
+}
+
+func (u *cartUsecase) getCartResponses(ctx context.Context, userID uint) ([]dtos.CartResponse, error) {
+	carts, err := u.cartRepo.GetCartsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cartResponses := []dtos.CartResponse{}
+	for _, cart := range carts {
+		cartResponses = append(cartResponses, dtos.CartResponse{
+			CartID:    cart.ID,
+			UserID:    cart.UserID,
+			ProductID: cart.ProductID,
+			Quantity:  cart.Quantity,
+			Price:     cart.Price,
+			CreatedAt: cart.CreatedAt,
+			UpdatedAt: cart.UpdatedAt,
+		})
+	}
+
+	return cartResponses, nil
+}
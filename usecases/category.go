@@ -1,18 +1,18 @@
 package usecases
 
 import (
-	"fmt"
+	"context"
 	"synapsis-backend/dtos"
 	"synapsis-backend/models"
 	"synapsis-backend/repositories"
 )
 
 type CategoryUsecase interface {
-	GetAllCategorys(page, limit int) ([]dtos.CategoryResponse, int, error)
-	GetCategoryByID(id uint) (dtos.CategoryResponse, error)
-	CreateCategory(category *dtos.CategoryInput) (dtos.CategoryResponse, error)
-	UpdateCategory(id uint, categoryInput dtos.CategoryInput) (dtos.CategoryResponse, error)
-	DeleteCategory(id uint) error
+	GetAllCategorys(ctx context.Context, page, limit int) ([]dtos.CategoryResponse, int, error)
+	GetCategoryByID(ctx context.Context, id uint) (dtos.CategoryResponse, error)
+	CreateCategory(ctx context.Context, category *dtos.CategoryInput) (dtos.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, id uint, categoryInput dtos.CategoryInput) (dtos.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, id uint) error
 }
 
 type categoryUsecase struct {
@@ -39,8 +39,8 @@ func NewCategoryUsecase(CategoryRepo repositories.CategoryRepository) CategoryUs
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /category [get]
 // @Security BearerAuth
-func (u *categoryUsecase) GetAllCategorys(page, limit int) ([]dtos.CategoryResponse, int, error) {
-	categorys, count, err := u.categoryRepo.GetAllCategorys(page, limit)
+func (u *categoryUsecase) GetAllCategorys(ctx context.Context, page, limit int) ([]dtos.CategoryResponse, int, error) {
+	categorys, count, err := u.categoryRepo.GetAllCategorys(ctx, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -74,9 +74,9 @@ func (u *categoryUsecase) GetAllCategorys(page, limit int) ([]dtos.CategoryRespo
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /category/{id} [get]
 // @Security BearerAuth
-func (u *categoryUsecase) GetCategoryByID(id uint) (dtos.CategoryResponse, error) {
+func (u *categoryUsecase) GetCategoryByID(ctx context.Context, id uint) (dtos.CategoryResponse, error) {
 	var categoryResponses dtos.CategoryResponse
-	category, err := u.categoryRepo.GetCategoryByID(id)
+	category, err := u.categoryRepo.GetCategoryByID(ctx, id)
 	if err != nil {
 		return categoryResponses, err
 	}
@@ -98,20 +98,21 @@ func (u *categoryUsecase) GetCategoryByID(id uint) (dtos.CategoryResponse, error
 // @Param        request body dtos.CategoryInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.CategoryStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /category [post]
 // @Security BearerAuth
-func (u *categoryUsecase) CreateCategory(category *dtos.CategoryInput) (dtos.CategoryResponse, error) {
+func (u *categoryUsecase) CreateCategory(ctx context.Context, category *dtos.CategoryInput) (dtos.CategoryResponse, error) {
 	var categoryResponses dtos.CategoryResponse
 
 	createCategory := models.Category{
 		Category: category.Category,
 	}
 
-	createdTrain, err := u.categoryRepo.CreateCategory(createCategory)
+	createdTrain, err := u.categoryRepo.CreateCategory(ctx, createCategory)
 	if err != nil {
 		return categoryResponses, err
 	}
@@ -136,26 +137,26 @@ func (u *categoryUsecase) CreateCategory(category *dtos.CategoryInput) (dtos.Cat
 // @Param        request body dtos.CategoryInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.CategoryStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /category/{id} [put]
 // @Security BearerAuth
-func (u *categoryUsecase) UpdateCategory(id uint, categoryInput dtos.CategoryInput) (dtos.CategoryResponse, error) {
+func (u *categoryUsecase) UpdateCategory(ctx context.Context, id uint, categoryInput dtos.CategoryInput) (dtos.CategoryResponse, error) {
 
 	var category models.Category
 	var categoryResponse dtos.CategoryResponse
 
-	category, err := u.categoryRepo.GetCategoryByID(id)
-	fmt.Println(category)
+	category, err := u.categoryRepo.GetCategoryByID(ctx, id)
 	if err != nil {
 		return categoryResponse, err
 	}
 
 	category.Category = categoryInput.Category
 
-	category, err = u.categoryRepo.UpdateCategory(category)
+	category, err = u.categoryRepo.UpdateCategory(ctx, category)
 
 	if err != nil {
 		return categoryResponse, err
@@ -185,12 +186,12 @@ func (u *categoryUsecase) UpdateCategory(id uint, categoryInput dtos.CategoryInp
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /category/{id} [delete]
 // @Security BearerAuth
-func (u *categoryUsecase) DeleteCategory(id uint) error {
-	category, err := u.categoryRepo.GetCategoryByID(id)
+func (u *categoryUsecase) DeleteCategory(ctx context.Context, id uint) error {
+	category, err := u.categoryRepo.GetCategoryByID(ctx, id)
 
 	if err != nil {
-		return nil
+		return err
 	}
-	err = u.categoryRepo.DeleteCategory(category)
+	err = u.categoryRepo.DeleteCategory(ctx, category)
 	return err
 }
@@ -0,0 +1,447 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/metrics"
+	"synapsis-backend/models"
+	"synapsis-backend/notifiers"
+	"synapsis-backend/repositories"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InventoryUsecase interface {
+	GetStockHistory(ctx context.Context, page, limit int, productID uint) ([]dtos.StockMovementResponse, int, error)
+	AdjustStock(ctx context.Context, userID, productID uint, input dtos.StockAdjustmentInput) (dtos.StockMovementResponse, error)
+	RecordStockMovement(ctx context.Context, movement models.StockMovement) (dtos.StockMovementResponse, error)
+	SetStockLevel(ctx context.Context, movement models.StockMovement, stock int) (dtos.StockMovementResponse, error)
+	ReturnOrderStock(ctx context.Context, orderID uint, reason, note string) error
+	GetStockAlerts(ctx context.Context, page, limit int, status string) ([]dtos.StockAlertResponse, int, error)
+	CheckStockLevel(ctx context.Context, productID uint) error
+	CheckLowStock(ctx context.Context) error
+	RecountStock(ctx context.Context, reconcile bool) ([]dtos.StockRecountResponse, error)
+}
+
+type inventoryUsecase struct {
+	stockMovementRepo repositories.StockMovementRepository
+	stockAlertRepo    repositories.StockAlertRepository
+	productRepo       repositories.ProductRepository
+	wishlistRepo      repositories.WishlistRepository
+	notifier          notifiers.Notifier
+	alertNotifier     notifiers.Notifier
+	log               *slog.Logger
+}
+
+// NewInventoryUsecase sends back-in-stock notifications to customers through
+// Notifier and low-stock alerts to the staff through AlertNotifier.
+func NewInventoryUsecase(
+	StockMovementRepo repositories.StockMovementRepository,
+	StockAlertRepo repositories.StockAlertRepository,
+	ProductRepo repositories.ProductRepository,
+	WishlistRepo repositories.WishlistRepository,
+	Notifier notifiers.Notifier,
+	AlertNotifier notifiers.Notifier,
+	Log *slog.Logger,
+) InventoryUsecase {
+	return &inventoryUsecase{StockMovementRepo, StockAlertRepo, ProductRepo, WishlistRepo, Notifier, AlertNotifier, Log}
+}
+
+// GetStockHistory godoc
+// @Summary      Get stock history
+// @Description  Get the stock movements of a product, newest first
+// @Tags         Product
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID product"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success      200 {object} dtos.GetAllStockMovementStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /product/{id}/stock-history [get]
+// @Security BearerAuth
+func (u *inventoryUsecase) GetStockHistory(ctx context.Context, page, limit int, productID uint) ([]dtos.StockMovementResponse, int, error) {
+	if _, err := u.productRepo.GetProductByID(ctx, productID); err != nil {
+		return nil, 0, apperrors.NotFound("Product not found")
+	}
+
+	movements, count, err := u.stockMovementRepo.GetStockMovementsByProductID(ctx, page, limit, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	movementResponses := []dtos.StockMovementResponse{}
+	for _, movement := range movements {
+		movementResponses = append(movementResponses, toStockMovementResponse(movement))
+	}
+
+	return movementResponses, count, nil
+}
+
+// AdjustStock godoc
+// @Summary      Adjust stock
+// @Description  Manually add or remove stock of a product. Reason is 'adjustment' or 'restock' and a note is required for the audit trail
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID product"
+// @Param        request body dtos.StockAdjustmentInput true "Payload Body [RAW]"
+// @Success      201 {object} dtos.StockMovementCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/product/{id}/stock-adjustments [post]
+// @Security BearerAuth
+func (u *inventoryUsecase) AdjustStock(ctx context.Context, userID, productID uint, input dtos.StockAdjustmentInput) (dtos.StockMovementResponse, error) {
+	var movementResponse dtos.StockMovementResponse
+
+	if input.Quantity == 0 {
+		return movementResponse, apperrors.Validation("Quantity must not be 0")
+	}
+	if input.Note == "" {
+		return movementResponse, apperrors.Validation("Note is required")
+	}
+
+	reason := input.Reason
+	if reason == "" {
+		reason = models.StockMovementReasonAdjustment
+	}
+	if reason != models.StockMovementReasonAdjustment && reason != models.StockMovementReasonRestock {
+		return movementResponse, apperrors.Validation("Reason must be 'adjustment' or 'restock'")
+	}
+	if reason == models.StockMovementReasonRestock && input.Quantity < 0 {
+		return movementResponse, apperrors.Validation("Restock quantity must be positive")
+	}
+
+	return u.RecordStockMovement(ctx, models.StockMovement{
+		ProductID:   productID,
+		UserID:      &userID,
+		WarehouseID: input.WarehouseID,
+		Reason:      reason,
+		Quantity:    input.Quantity,
+		Note:        input.Note,
+	})
+}
+
+// RecordStockMovement applies movement to the product stock and appends it to
+// the ledger. It and SetStockLevel are the only ways stock is changed.
+func (u *inventoryUsecase) RecordStockMovement(ctx context.Context, movement models.StockMovement) (dtos.StockMovementResponse, error) {
+	var movementResponse dtos.StockMovementResponse
+
+	movement, err := u.stockMovementRepo.ApplyStockMovement(ctx, movement)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInsufficientStock) {
+			return movementResponse, apperrors.Conflict("Insufficient stock for product %d", movement.ProductID)
+		}
+		return movementResponse, err
+	}
+
+	repositories.AfterCommit(ctx, func(ctx context.Context) {
+		u.stockMoved(ctx, movement)
+	})
+	return toStockMovementResponse(movement), nil
+}
+
+// SetStockLevel records the movement that brings the stock of the product
+// to stock. The quantity of movement is worked out under the lock of the
+// product, so it is right even when the stock changes meanwhile. Nothing is
+// recorded when the stock already is stock.
+func (u *inventoryUsecase) SetStockLevel(ctx context.Context, movement models.StockMovement, stock int) (dtos.StockMovementResponse, error) {
+	var movementResponse dtos.StockMovementResponse
+
+	if stock < 0 {
+		return movementResponse, apperrors.Validation("Stock must not be negative")
+	}
+
+	productID := movement.ProductID
+	movement, err := u.stockMovementRepo.SetStockLevel(ctx, movement, stock)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInsufficientStock) {
+			return movementResponse, apperrors.Conflict("Insufficient stock for product %d", productID)
+		}
+		return movementResponse, err
+	}
+	if movement.ID == 0 {
+		// The threshold may have changed even though the stock did not.
+		repositories.AfterCommit(ctx, func(ctx context.Context) {
+			if err := u.CheckStockLevel(ctx, productID); err != nil {
+				u.log.ErrorContext(ctx, "failed to check stock level", slog.Any("error", err))
+			}
+		})
+		return movementResponse, nil
+	}
+
+	repositories.AfterCommit(ctx, func(ctx context.Context) {
+		u.stockMoved(ctx, movement)
+	})
+	return toStockMovementResponse(movement), nil
+}
+
+// stockMoved sends the notifications and alerts a movement calls for. It
+// runs after the movement is committed, so a slow mail server or webhook
+// does not hold the lock on the product.
+func (u *inventoryUsecase) stockMoved(ctx context.Context, movement models.StockMovement) {
+	if movement.StockBefore <= 0 && movement.StockAfter > 0 {
+		u.notifyBackInStock(ctx, movement.ProductID)
+	}
+	if movement.StockBefore > 0 && movement.StockAfter <= 0 {
+		metrics.StockOuts.Inc()
+	}
+	if err := u.CheckStockLevel(ctx, movement.ProductID); err != nil {
+		u.log.ErrorContext(ctx, "failed to check stock level", slog.Any("error", err))
+	}
+}
+
+// ReturnOrderStock returns the stock the order still holds to where it was
+// taken from, recording it with reason. The stock is read from the ledger,
+// not from the order details, so only what the order really took comes back.
+func (u *inventoryUsecase) ReturnOrderStock(ctx context.Context, orderID uint, reason, note string) error {
+	held, err := u.stockMovementRepo.GetOrderHeldStock(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	for _, stock := range held {
+		_, err := u.RecordStockMovement(ctx, models.StockMovement{
+			ProductID:   stock.ProductID,
+			OrderID:     &orderID,
+			WarehouseID: stock.WarehouseID,
+			Reason:      reason,
+			Quantity:    stock.Quantity,
+			Note:        note,
+		})
+		if err != nil {
+			return fmt.Errorf("return stock of order %d: %w", orderID, err)
+		}
+	}
+	return nil
+}
+
+// GetStockAlerts godoc
+// @Summary      Get stock alerts
+// @Description  Get low-stock alerts, newest first
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param status query string false "Search by status like 'open' or 'resolved'"
+// @Success      200 {object} dtos.GetAllStockAlertStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/inventory/alerts [get]
+// @Security BearerAuth
+func (u *inventoryUsecase) GetStockAlerts(ctx context.Context, page, limit int, status string) ([]dtos.StockAlertResponse, int, error) {
+	if status != "" && status != "open" && status != "resolved" {
+		return nil, 0, apperrors.Validation("Status must be 'open' or 'resolved'")
+	}
+
+	alerts, count, err := u.stockAlertRepo.GetAllStockAlerts(ctx, page, limit, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	alertResponses := []dtos.StockAlertResponse{}
+	for _, alert := range alerts {
+		alertResponse := dtos.StockAlertResponse{
+			StockAlertID: alert.ID,
+			ProductID:    alert.ProductID,
+			Stock:        alert.Stock,
+			Threshold:    alert.Threshold,
+			Status:       "open",
+			ResolvedAt:   alert.ResolvedAt,
+			CreatedAt:    alert.CreatedAt,
+		}
+		if alert.ResolvedAt != nil {
+			alertResponse.Status = "resolved"
+		}
+
+		product, err := u.productRepo.GetProductByID(ctx, alert.ProductID)
+		if err == nil {
+			alertResponse.ProductName = product.Name
+			alertResponse.CurrentStock = product.Stock
+		}
+
+		alertResponses = append(alertResponses, alertResponse)
+	}
+
+	return alertResponses, count, nil
+}
+
+// CheckStockLevel raises a low-stock alert when the stock of the product is at
+// or below its reorder threshold and there is no open alert yet, and resolves
+// the open alert once the stock is back above the threshold.
+func (u *inventoryUsecase) CheckStockLevel(ctx context.Context, productID uint) error {
+	// A deleted product is never low on stock, so its open alert is resolved.
+	product, err := u.productRepo.GetProductByID(ctx, productID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	alert, err := u.stockAlertRepo.GetOpenStockAlert(ctx, productID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	hasOpenAlert := err == nil
+
+	lowStock := product.ReorderThreshold > 0 && product.Stock <= product.ReorderThreshold
+
+	switch {
+	case lowStock && !hasOpenAlert:
+		alert, err = u.stockAlertRepo.CreateStockAlert(ctx, models.StockAlert{
+			ProductID: product.ID,
+			Stock:     product.Stock,
+			Threshold: product.ReorderThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = u.alertNotifier.Notify(ctx, notifiers.Notification{
+			Subject: "Low stock",
+			Message: fmt.Sprintf("%s (product %d) is low on stock: %d left, reorder threshold is %d", product.Name, product.ID, product.Stock, product.ReorderThreshold),
+		})
+		if err != nil {
+			u.log.ErrorContext(ctx, "failed to send low stock alert", slog.Any("error", err))
+		}
+	case !lowStock && hasOpenAlert:
+		resolvedAt := time.Now()
+		alert.ResolvedAt = &resolvedAt
+		if _, err := u.stockAlertRepo.UpdateStockAlert(ctx, alert); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckLowStock checks the stock level of every product that is low on stock
+// or has an open alert. It catches changes that did not go through
+// RecordStockMovement, such as a lowered reorder threshold.
+func (u *inventoryUsecase) CheckLowStock(ctx context.Context) error {
+	products, err := u.productRepo.GetLowStockProducts(ctx)
+	if err != nil {
+		return err
+	}
+	alerts, err := u.stockAlertRepo.GetOpenStockAlerts(ctx)
+	if err != nil {
+		return err
+	}
+
+	productIDs := map[uint]bool{}
+	for _, product := range products {
+		productIDs[product.ID] = true
+	}
+	for _, alert := range alerts {
+		productIDs[alert.ProductID] = true
+	}
+
+	for productID := range productIDs {
+		if err := u.CheckStockLevel(ctx, productID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecountStock lists the products whose stock does not match the ledger or
+// is less than the stock assigned to their warehouses. With reconcile, an
+// adjustment movement is recorded for every product whose ledger is off.
+// Warehouse stock is only reported, it cannot be told which one is wrong.
+func (u *inventoryUsecase) RecountStock(ctx context.Context, reconcile bool) ([]dtos.StockRecountResponse, error) {
+	discrepancies, err := u.stockMovementRepo.GetStockDiscrepancies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recountResponses := []dtos.StockRecountResponse{}
+	for _, discrepancy := range discrepancies {
+		recountResponse := dtos.StockRecountResponse{
+			ProductID:     discrepancy.ProductID,
+			ProductName:   discrepancy.ProductName,
+			Stock:         discrepancy.Stock,
+			LedgerStock:   discrepancy.LedgerStock,
+			AssignedStock: discrepancy.AssignedStock,
+		}
+
+		if reconcile && discrepancy.Stock != discrepancy.LedgerStock {
+			movement, err := u.stockMovementRepo.ReconcileStockLedger(ctx, discrepancy.ProductID, "Recorded by stock recount")
+			if err != nil {
+				return recountResponses, err
+			}
+			recountResponse.Reconciled = movement.ID != 0
+		}
+
+		recountResponses = append(recountResponses, recountResponse)
+	}
+
+	return recountResponses, nil
+}
+
+// notifyBackInStock tells every user who asked to be notified that the
+// product is available again. The notification is sent once; users have to
+// turn it on again through their wishlist to be notified the next time.
+func (u *inventoryUsecase) notifyBackInStock(ctx context.Context, productID uint) {
+	product, err := u.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to get product", slog.Any("error", err))
+		return
+	}
+
+	wishlists, err := u.wishlistRepo.GetWishlistsAwaitingStock(ctx, product.ID)
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to get wishlists awaiting stock", slog.Any("error", err))
+		return
+	}
+
+	for _, wishlist := range wishlists {
+		err := u.notifier.Notify(ctx, notifiers.Notification{
+			UserID:  wishlist.UserID,
+			Subject: "Back in stock",
+			Message: fmt.Sprintf("%s is back in stock", product.Name),
+		})
+		if err != nil {
+			u.log.ErrorContext(ctx, "failed to send back in stock notification", slog.Any("error", err))
+			continue
+		}
+
+		notifiedAt := time.Now()
+		wishlist.NotifyWhenInStock = false
+		wishlist.NotifiedAt = &notifiedAt
+		if _, err := u.wishlistRepo.UpdateWishlist(ctx, wishlist); err != nil {
+			u.log.ErrorContext(ctx, "failed to update wishlist", slog.Any("error", err))
+		}
+	}
+}
+
+func toStockMovementResponse(movement models.StockMovement) dtos.StockMovementResponse {
+	return dtos.StockMovementResponse{
+		StockMovementID: movement.ID,
+		ProductID:       movement.ProductID,
+		UserID:          movement.UserID,
+		OrderID:         movement.OrderID,
+		WarehouseID:     movement.WarehouseID,
+		Reason:          movement.Reason,
+		Quantity:        movement.Quantity,
+		StockBefore:     movement.StockBefore,
+		StockAfter:      movement.StockAfter,
+		Note:            movement.Note,
+		CreatedAt:       movement.CreatedAt,
+	}
+}
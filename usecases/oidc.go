@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetOIDCAuthURL godoc
+// @Summary      Login with an OpenID Connect provider
+// @Description  Redirect to the login page of the provider. The provider sends the user back to /auth/{provider}/callback
+// @Tags         User
+// @Param provider path string true "Provider name"
+// @Success      302
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /auth/{provider} [get]
+func (u *userUsecase) GetOIDCAuthURL(ctx context.Context, provider string) (dtos.OIDCAuthResponse, error) {
+	var authResponse dtos.OIDCAuthResponse
+
+	oidcProvider, ok := u.oidcProviders[provider]
+	if !ok {
+		return authResponse, errors.New("Unknown login provider")
+	}
+
+	nonce, err := helpers.GenerateRandomToken(16)
+	if err != nil {
+		return authResponse, err
+	}
+	state, err := middlewares.CreateOIDCStateToken(provider, nonce)
+	if err != nil {
+		return authResponse, err
+	}
+
+	authURL, err := oidcProvider.AuthCodeURL(state, nonce)
+	if err != nil {
+		return authResponse, err
+	}
+
+	authResponse.AuthURL = authURL
+	authResponse.State = state
+	return authResponse, nil
+}
+
+// OIDCCallback godoc
+// @Summary      OpenID Connect callback
+// @Description  Finish the login with the provider. Accounts are linked by verified email, unknown emails get a new account
+// @Tags         User
+// @Produce      json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success      200 {object} dtos.UserStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /auth/{provider}/callback [get]
+func (u *userUsecase) OIDCCallback(ctx context.Context, provider string, input dtos.OIDCCallbackInput, cookieState, ip string) (dtos.UserInformationResponse, error) {
+	var userResponse dtos.UserInformationResponse
+
+	oidcProvider, ok := u.oidcProviders[provider]
+	if !ok {
+		return userResponse, errors.New("Unknown login provider")
+	}
+	if input.Error != "" {
+		return userResponse, fmt.Errorf("Login was cancelled: %s", input.Error)
+	}
+
+	// The state must come back to the browser that started the login,
+	// otherwise someone could log the user into the attacker's account.
+	if input.State == "" || input.State != cookieState {
+		return userResponse, errors.New("Invalid login state")
+	}
+	stateProvider, nonce, err := middlewares.ParseOIDCStateToken(input.State)
+	if err != nil || stateProvider != provider {
+		return userResponse, errors.New("Invalid login state")
+	}
+
+	claims, err := oidcProvider.Exchange(input.Code, nonce)
+	if err != nil {
+		return userResponse, err
+	}
+
+	user, err := u.findOrCreateOIDCUser(ctx, provider, claims.Subject, claims.Email, claims.EmailVerified, claims.Name)
+	if err != nil {
+		return userResponse, err
+	}
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return userResponse, apperrors.Forbidden("Account is locked until %s", user.LockedUntil.Format(time.RFC3339))
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return twoFactorChallenge(user)
+	}
+
+	return u.completeLogin(ctx, user, ip)
+}
+
+// findOrCreateOIDCUser returns the user linked to the provider account. An
+// account is only linked to an existing user when both the provider and the
+// user have verified the email, otherwise anyone could claim an account by
+// its email.
+func (u *userUsecase) findOrCreateOIDCUser(ctx context.Context, provider, subject, email string, emailVerified bool, name string) (models.User, error) {
+	identity, err := u.userIdentityRepo.GetUserIdentity(ctx, provider, subject)
+	if err == nil {
+		return u.userRepo.UserGetById(ctx, identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, err
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return models.User{}, errors.New("Login provider did not share an email")
+	}
+
+	identity = models.UserIdentity{Provider: provider, Subject: subject, Email: email}
+
+	user, err := u.userRepo.UserGetByEmail(ctx, email)
+	if err == nil {
+		if !emailVerified {
+			return models.User{}, apperrors.Conflict("Email is already registered, login with your password instead")
+		}
+		// Whoever registered an unverified account never proved they own the
+		// email, linking it would hand the provider account their password
+		// and sessions.
+		if user.EmailVerifiedAt == nil {
+			return models.User{}, apperrors.Conflict("Email is already registered but not verified, verify it before logging in with %s", provider)
+		}
+		identity.UserID = user.ID
+		if _, err := u.userIdentityRepo.CreateUserIdentity(ctx, identity); err != nil {
+			return models.User{}, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, err
+	}
+
+	// The account has no usable password, the user can set one through
+	// /forgot-password.
+	randomPassword, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return models.User{}, err
+	}
+	password, err := helpers.HashPassword(randomPassword)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if name == "" {
+		name = email
+	}
+	user = models.User{
+		FullName: name,
+		Email:    email,
+		Password: password,
+		Citizen:  "Indonesia",
+		Role:     models.RoleUser,
+	}
+	if emailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	return u.userIdentityRepo.CreateUserWithIdentity(ctx, user, identity)
+}
@@ -1,26 +1,39 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"synapsis-backend/apperrors"
 	"synapsis-backend/dtos"
+	"synapsis-backend/metrics"
 	"synapsis-backend/models"
 	"synapsis-backend/repositories"
+	"time"
 )
 
 type OrderUsecase interface {
-	GetAllOrders(page, limit int, status string) ([]dtos.OrderResponse, int, error)
-	GetOrderByID(id uint) (dtos.OrderResponse, error)
-	CreateOrder(order *dtos.OrderInput) (dtos.OrderResponse, error)
-	Checkout(order *dtos.OrderInputCheckout) (dtos.OrderResponseCheckout, error)
-	UpdateOrder(id uint, orderInput dtos.OrderInput) (dtos.OrderResponse, error)
-	DeleteOrder(id uint) error
+	GetAllOrders(ctx context.Context, page, limit int, status string) ([]dtos.OrderResponse, int, error)
+	GetOrderByID(ctx context.Context, id uint) (dtos.OrderResponse, error)
+	GetOrdersByUserID(ctx context.Context, page, limit int, userID uint) ([]dtos.OrderResponse, int, error)
+	CreateOrder(ctx context.Context, order *dtos.OrderInput) (dtos.OrderResponse, error)
+	Checkout(ctx context.Context, order *dtos.OrderInputCheckout) (dtos.OrderResponseCheckout, error)
+	UpdateOrder(ctx context.Context, id uint, orderInput dtos.OrderInput) (dtos.OrderResponse, error)
+	DeleteOrder(ctx context.Context, id uint) error
+	ExpireOrders(ctx context.Context, olderThan time.Duration) ([]uint, error)
 }
 
 type orderUsecase struct {
-	orderRepo       repositories.OrderRepository
-	cartRepo        repositories.CartRepository
-	productRepo     repositories.ProductRepository
-	orderDetailRepo repositories.OrderDetailRepository
+	orderRepo          repositories.OrderRepository
+	cartRepo           repositories.CartRepository
+	productRepo        repositories.ProductRepository
+	orderDetailRepo    repositories.OrderDetailRepository
+	warehouseRepo      repositories.WarehouseRepository
+	userRepo           repositories.UserRepository
+	transactor         repositories.Transactor
+	inventoryUsecase   InventoryUsecase
+	allocationStrategy AllocationStrategy
+	log                *slog.Logger
 }
 
 func NewOrderUsecase(
@@ -28,9 +41,21 @@ func NewOrderUsecase(
 	CartRepo repositories.CartRepository,
 	ProdutRepo repositories.ProductRepository,
 	OrderDetailRepo repositories.OrderDetailRepository,
-
+	WarehouseRepo repositories.WarehouseRepository,
+	UserRepo repositories.UserRepository,
+	Transactor repositories.Transactor,
+	InventoryUsecase InventoryUsecase,
+	AllocationStrategy AllocationStrategy,
+	Log *slog.Logger,
 ) OrderUsecase {
-	return &orderUsecase{OrderRepo, CartRepo, ProdutRepo, OrderDetailRepo}
+	return &orderUsecase{OrderRepo, CartRepo, ProdutRepo, OrderDetailRepo, WarehouseRepo, UserRepo, Transactor, InventoryUsecase, AllocationStrategy, Log}
+}
+
+// warehouseAllocation is the part of an order line shipped from one
+// warehouse, or from the unassigned stock when WarehouseID is nil.
+type warehouseAllocation struct {
+	WarehouseID *uint
+	Quantity    int
 }
 
 // GetAllOrders godoc
@@ -50,15 +75,15 @@ func NewOrderUsecase(
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /order [get]
 // @Security BearerAuth
-func (u *orderUsecase) GetAllOrders(page, limit int, status string) ([]dtos.OrderResponse, int, error) {
-	orders, count, err := u.orderRepo.GetAllOrders(page, limit, status)
+func (u *orderUsecase) GetAllOrders(ctx context.Context, page, limit int, status string) ([]dtos.OrderResponse, int, error) {
+	orders, count, err := u.orderRepo.GetAllOrders(ctx, page, limit, status)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	var orderResponses []dtos.OrderResponse
 	for _, order := range orders {
-		// category, err := u.orderRepo.GetCategoryByID(order.CategoryID)
+		// category, err := u.orderRepo.GetCategoryByID(ctx, order.CategoryID)
 
 		orderResponse := dtos.OrderResponse{
 			OrderID:    order.ID,
@@ -74,6 +99,44 @@ func (u *orderUsecase) GetAllOrders(page, limit int, status string) ([]dtos.Orde
 	return orderResponses, count, nil
 }
 
+// GetOrdersByUserID godoc
+// @Summary      Get orders of a user
+// @Description  Get the orders of a user, newest first
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success      200 {object} dtos.GetAllOrderStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id}/orders [get]
+// @Security BearerAuth
+func (u *orderUsecase) GetOrdersByUserID(ctx context.Context, page, limit int, userID uint) ([]dtos.OrderResponse, int, error) {
+	orders, count, err := u.orderRepo.GetOrdersByUserID(ctx, page, limit, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orderResponses := []dtos.OrderResponse{}
+	for _, order := range orders {
+		orderResponses = append(orderResponses, dtos.OrderResponse{
+			OrderID:    order.ID,
+			TotalPrice: order.TotalPrice,
+			UserID:     order.UserID,
+			Status:     order.Status,
+			CreatedAt:  order.CreatedAt,
+			UpdatedAt:  order.UpdatedAt,
+		})
+	}
+
+	return orderResponses, count, nil
+}
+
 // GetOrderByID godoc
 // @Summary      Get order by ID
 // @Description  Get order by ID
@@ -89,9 +152,9 @@ func (u *orderUsecase) GetAllOrders(page, limit int, status string) ([]dtos.Orde
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /order/{id} [get]
 // @Security BearerAuth
-func (u *orderUsecase) GetOrderByID(id uint) (dtos.OrderResponse, error) {
+func (u *orderUsecase) GetOrderByID(ctx context.Context, id uint) (dtos.OrderResponse, error) {
 	var orderResponses dtos.OrderResponse
-	order, err := u.orderRepo.GetOrderByID(id)
+	order, err := u.orderRepo.GetOrderByID(ctx, id)
 	if err != nil {
 		return orderResponses, err
 	}
@@ -115,22 +178,23 @@ func (u *orderUsecase) GetOrderByID(id uint) (dtos.OrderResponse, error) {
 // @Param        request body dtos.OrderInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.OrderStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /order [post]
 // @Security BearerAuth
-func (u *orderUsecase) CreateOrder(order *dtos.OrderInput) (dtos.OrderResponse, error) {
+func (u *orderUsecase) CreateOrder(ctx context.Context, order *dtos.OrderInput) (dtos.OrderResponse, error) {
 	var orderResponses dtos.OrderResponse
 
 	createOrder := models.Order{
 		UserID:     order.UserID,
 		TotalPrice: order.TotalPrice,
-		Status:     "unpaid",
+		Status:     models.OrderStatusUnpaid,
 	}
 
-	createdOrder, err := u.orderRepo.CreateOrder(createOrder)
+	createdOrder, err := u.orderRepo.CreateOrder(ctx, createOrder)
 	if err != nil {
 		return orderResponses, err
 	}
@@ -149,103 +213,238 @@ func (u *orderUsecase) CreateOrder(order *dtos.OrderInput) (dtos.OrderResponse,
 
 // CreateOrder godoc
 // @Summary      Create a new order from cart
-// @Description  Create a new order from cart
+// @Description  Create a new order from cart. The order is priced like the cart summary: current product prices, bulk discount and tax
 // @Tags         Cart
 // @Accept       json
 // @Produce      json
 // @Param        request body dtos.OrderInputCheckout. true "Payload Body [RAW]"
 // @Success      200 {object} dtos.OrderCheckoutStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /cart/checkout [post]
 // @Security BearerAuth
-func (u *orderUsecase) Checkout(order *dtos.OrderInputCheckout) (dtos.OrderResponseCheckout, error) {
+func (u *orderUsecase) Checkout(ctx context.Context, order *dtos.OrderInputCheckout) (dtos.OrderResponseCheckout, error) {
+	orderResponse, err := u.checkout(ctx, order)
+	if err != nil {
+		metrics.CheckoutFailures.WithLabelValues(checkoutFailureReason(err)).Inc()
+		return orderResponse, err
+	}
+	metrics.Checkouts.Inc()
+	return orderResponse, nil
+}
+
+// errCartEmpty is returned by checkout when the user has nothing to order.
+var errCartEmpty = errors.New("Cart is empty")
+
+// checkoutFailureReason is the reason label of a failed checkout.
+func checkoutFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errCartEmpty):
+		return "empty_cart"
+	case apperrors.KindOf(err) == apperrors.KindConflict:
+		return "insufficient_stock"
+	case apperrors.KindOf(err) == apperrors.KindForbidden:
+		return "email_not_verified"
+	case apperrors.KindOf(err) == apperrors.KindNotFound:
+		return "not_found"
+	}
+	return "error"
+}
+
+func (u *orderUsecase) checkout(ctx context.Context, order *dtos.OrderInputCheckout) (dtos.OrderResponseCheckout, error) {
 	var orderResponses dtos.OrderResponseCheckout
+	if err := ensureEmailVerified(ctx, u.userRepo, order.UserID); err != nil {
+		return orderResponses, err
+	}
+
 	// First We need to get all carts by user_id
-	totalPrice := 0
 	page, limit := 1, 100
-	carts, _, err := u.cartRepo.GetAllCarts(page, limit, int(order.UserID))
+	carts, _, err := u.cartRepo.GetAllCarts(ctx, page, limit, int(order.UserID))
 	if err != nil {
 		return orderResponses, err
 	}
 	if len(carts) == 0 {
-		return orderResponses, errors.New("Cart is empty")
+		return orderResponses, errCartEmpty
 	}
 
-	for _, cart := range carts {
-		totalPrice += cart.Price
+	// Second We price the carts at the current product prices, the same way
+	// as the cart summary
+	lines := make([]linePrice, len(carts))
+	for i, cart := range carts {
+		product, err := u.productRepo.GetProductByID(ctx, cart.ProductID)
+		if err != nil {
+			return orderResponses, err
+		}
+		if !product.Status {
+			return orderResponses, apperrors.Conflict("Product %d is not available", product.ID)
+		}
+		if product.Stock < cart.Quantity {
+			return orderResponses, apperrors.Conflict("Insufficient stock for product %d", product.ID)
+		}
+		lines[i] = priceLine(product.Price, cart.Quantity)
 	}
-	// Second We Need to add all price from carts
 	// Then We need to create order
 	createOrder := models.Order{
-		UserID:     order.UserID,
-		TotalPrice: totalPrice,
-		Status:     "unpaid",
+		UserID:            order.UserID,
+		TotalPrice:        priceCart(lines).GrandTotal,
+		Status:            models.OrderStatusUnpaid,
+		ShippingAddress:   order.ShippingAddress,
+		ShippingLatitude:  order.ShippingLatitude,
+		ShippingLongitude: order.ShippingLongitude,
 	}
 
-	createdOrder, err := u.orderRepo.CreateOrder(createOrder)
+	// The order, its stock movements, its details and the emptied cart are
+	// written in one transaction, so a line that fails leaves nothing behind.
+	var createdOrder models.Order
+	orderDetailResponses := []dtos.OrderDetailResponse{}
+	err = u.transactor.Transaction(ctx, func(ctx context.Context) error {
+		createdOrder, err = u.orderRepo.CreateOrder(ctx, createOrder)
+		if err != nil {
+			return err
+		}
+
+		// Third We need to update stock from product
+		// we make record Data in order_detail Table
+		// A line is split into one order detail per warehouse it ships from
+		for i, cart := range carts {
+			allocations, err := u.allocateWarehouses(ctx, cart.ProductID, cart.Quantity, createdOrder)
+			if err != nil {
+				return err
+			}
+
+			lineTotal := lines[i].Total
+			subTotalLeft := lineTotal
+			for i, allocation := range allocations {
+				_, err := u.inventoryUsecase.RecordStockMovement(ctx, models.StockMovement{
+					ProductID:   cart.ProductID,
+					UserID:      &createdOrder.UserID,
+					OrderID:     &createdOrder.ID,
+					WarehouseID: allocation.WarehouseID,
+					Reason:      models.StockMovementReasonSale,
+					Quantity:    -allocation.Quantity,
+				})
+				if err != nil {
+					return err
+				}
+
+				subTotal := lineTotal * allocation.Quantity / cart.Quantity
+				if i == len(allocations)-1 {
+					subTotal = subTotalLeft
+				}
+				subTotalLeft -= subTotal
+
+				// we Create Order Detail
+				createOrderDetail := models.OrderDetail{
+					ProductID:   cart.ProductID,
+					OrderID:     createdOrder.ID,
+					WarehouseID: allocation.WarehouseID,
+					Quantity:    allocation.Quantity,
+					SubTotal:    subTotal,
+				}
+
+				createdOrderDetail, err := u.orderDetailRepo.CreateOrderDetail(ctx, createOrderDetail)
+				if err != nil {
+					return err
+				}
+				orderDetail := dtos.OrderDetailResponse{
+					OrderDetailID: createdOrderDetail.ID,
+					ProductID:     createdOrderDetail.ProductID,
+					OrderID:       createdOrderDetail.OrderID,
+					WarehouseID:   createdOrderDetail.WarehouseID,
+					Quantity:      createdOrderDetail.Quantity,
+					SubTotal:      createdOrderDetail.SubTotal,
+					CreatedAt:     createdOrderDetail.CreatedAt,
+					UpdatedAt:     createdOrderDetail.UpdatedAt,
+				}
+
+				orderDetailResponses = append(orderDetailResponses, orderDetail)
+			}
+			// And delete all carts
+			err = u.cartRepo.DeleteCart(ctx, cart)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return orderResponses, err
 	}
+	u.log.InfoContext(ctx, "order created",
+		slog.Uint64("order_id", uint64(createdOrder.ID)),
+		slog.Int("total_price", createdOrder.TotalPrice),
+	)
 
-	orderDetailResponses := []dtos.OrderDetailResponse{}
-	// Third We need to update stock from product
-	// we make record Data in order_detail Table
-	for _, cart := range carts {
-		product, err := u.productRepo.GetProductByID(cart.ProductID)
-		if err != nil {
-			return orderResponses, err
+	orderResponse := dtos.OrderResponseCheckout{
+		OrderID:         createdOrder.ID,
+		TotalPrice:      createdOrder.TotalPrice,
+		UserID:          createdOrder.UserID,
+		Status:          createdOrder.Status,
+		ShippingAddress: createdOrder.ShippingAddress,
+		OrderDetail:     orderDetailResponses,
+		CreatedAt:       createdOrder.CreatedAt,
+		UpdatedAt:       createdOrder.UpdatedAt,
+	}
+
+	return orderResponse, nil
+}
+
+// allocateWarehouses splits quantity of the product over the warehouses in
+// the order picked by the allocation strategy. Whatever the warehouses cannot
+// cover is taken from the unassigned stock of the product.
+func (u *orderUsecase) allocateWarehouses(ctx context.Context, productID uint, quantity int, order models.Order) ([]warehouseAllocation, error) {
+	product, err := u.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, err := u.warehouseRepo.GetStocksByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := 0
+	candidates := []WarehouseCandidate{}
+	for _, stock := range stocks {
+		assigned += stock.Stock
+		if stock.Stock <= 0 {
+			continue
 		}
-		product.Stock -= cart.Quantity
-		_, err = u.productRepo.UpdateProduct(product)
+		warehouse, err := u.warehouseRepo.GetWarehouseByID(ctx, stock.WarehouseID)
 		if err != nil {
-			return orderResponses, err
-		}
-		// we Create Order Detail
-		createOrderDetail := models.OrderDetail{
-			ProductID: product.ID,
-			OrderID:   createdOrder.ID,
-			Quantity:  cart.Quantity,
-			SubTotal:  cart.Price,
+			return nil, err
 		}
+		candidates = append(candidates, WarehouseCandidate{Warehouse: warehouse, Stock: stock.Stock})
+	}
 
-		createdOrderDetail, err := u.orderDetailRepo.CreateOrderDetail(createOrderDetail)
-		if err != nil {
-			return orderResponses, err
+	allocations := []warehouseAllocation{}
+	remaining := quantity
+	for _, candidate := range u.allocationStrategy.Rank(candidates, order) {
+		if remaining == 0 {
+			break
 		}
-		orderDetail := dtos.OrderDetailResponse{
-			OrderDetailID: createdOrderDetail.ID,
-			ProductID:     createdOrderDetail.ProductID,
-			OrderID:       createdOrderDetail.OrderID,
-			Quantity:      createdOrderDetail.Quantity,
-			SubTotal:      createdOrderDetail.SubTotal,
-			CreatedAt:     createdOrderDetail.CreatedAt,
-			UpdatedAt:     createdOrderDetail.UpdatedAt,
+		take := candidate.Stock
+		if take > remaining {
+			take = remaining
 		}
-
-		orderDetailResponses = append(orderDetailResponses, orderDetail)
-		// And delete all carts
-		err = u.cartRepo.DeleteCart(cart)
-		if err != nil {
-			return orderResponses, err
-		}
-
+		warehouseID := candidate.Warehouse.ID
+		allocations = append(allocations, warehouseAllocation{WarehouseID: &warehouseID, Quantity: take})
+		remaining -= take
 	}
 
-	orderResponse := dtos.OrderResponseCheckout{
-		OrderID:     createdOrder.ID,
-		TotalPrice:  createdOrder.TotalPrice,
-		UserID:      createdOrder.UserID,
-		Status:      createdOrder.Status,
-		OrderDetail: orderDetailResponses,
-		CreatedAt:   createdOrder.CreatedAt,
-		UpdatedAt:   createdOrder.UpdatedAt,
+	if remaining > 0 {
+		if product.Stock-assigned < remaining {
+			return nil, apperrors.Conflict("Insufficient stock for product %d", productID)
+		}
+		allocations = append(allocations, warehouseAllocation{Quantity: remaining})
 	}
 
-	return orderResponse, nil
+	return allocations, nil
 }
 
 // UpdateOrder godoc
@@ -258,18 +457,19 @@ func (u *orderUsecase) Checkout(order *dtos.OrderInputCheckout) (dtos.OrderRespo
 // @Param        request body dtos.OrderInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.OrderStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /order/{id} [put]
 // @Security BearerAuth
-func (u *orderUsecase) UpdateOrder(id uint, orderInput dtos.OrderInput) (dtos.OrderResponse, error) {
+func (u *orderUsecase) UpdateOrder(ctx context.Context, id uint, orderInput dtos.OrderInput) (dtos.OrderResponse, error) {
 
 	var order models.Order
 	var orderResponse dtos.OrderResponse
 
-	order, err := u.orderRepo.GetOrderByID(id)
+	order, err := u.orderRepo.GetOrderByID(ctx, id)
 	if err != nil {
 		return orderResponse, err
 	}
@@ -278,7 +478,7 @@ func (u *orderUsecase) UpdateOrder(id uint, orderInput dtos.OrderInput) (dtos.Or
 	order.UserID = orderInput.UserID
 	order.TotalPrice = orderInput.TotalPrice
 	// order.Status = orderInput.Status
-	order, err = u.orderRepo.UpdateOrder(order)
+	order, err = u.orderRepo.UpdateOrder(ctx, order)
 
 	if err != nil {
 		return orderResponse, err
@@ -297,7 +497,7 @@ func (u *orderUsecase) UpdateOrder(id uint, orderInput dtos.OrderInput) (dtos.Or
 
 // DeleteOrder godoc
 // @Summary      Delete a order
-// @Description  Delete a order
+// @Description  Delete a order. An unpaid order is cancelled and a paid order refunded first, returning its stock.
 // @Tags         Order
 // @Accept       json
 // @Produce      json
@@ -307,15 +507,107 @@ func (u *orderUsecase) UpdateOrder(id uint, orderInput dtos.OrderInput) (dtos.Or
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /order/{id} [delete]
 // @Security BearerAuth
-func (u *orderUsecase) DeleteOrder(id uint) error {
-	order, err := u.orderRepo.GetOrderByID(id)
+func (u *orderUsecase) DeleteOrder(ctx context.Context, id uint) error {
+	order, err := u.orderRepo.GetOrderByID(ctx, id)
 
 	if err != nil {
-		return nil
+		return err
+	}
+
+	// The stock the order still holds is returned in the same transaction
+	// as the deletion.
+	err = u.transactor.Transaction(ctx, func(ctx context.Context) error {
+		released, err := releaseOrderStock(ctx, u.orderRepo, u.inventoryUsecase, order, "Order deleted")
+		if err != nil {
+			return err
+		}
+		if !released && orderHoldsStock(order) {
+			return apperrors.Conflict("Order %d was changed, try again", order.ID)
+		}
+		return u.orderRepo.DeleteOrder(ctx, order)
+	})
+	if err != nil {
+		return err
+	}
+	u.log.InfoContext(ctx, "order deleted", slog.Uint64("order_id", uint64(order.ID)), slog.String("status", order.Status))
+	return nil
+}
+
+// releaseOrderStock returns the stock held by the order to where it was
+// taken from. An unpaid order is cancelled and its stock recorded as a
+// cancellation, a paid order is refunded and its stock recorded as a refund.
+// Delivered, cancelled and refunded orders hold no stock and are left alone.
+// It reports whether it released the order, which it does not when the
+// order holds no stock or its status changed since it was read. Call it in a
+// transaction, so the status and the movements change together.
+func releaseOrderStock(
+	ctx context.Context,
+	orderRepo repositories.OrderRepository,
+	inventoryUsecase InventoryUsecase,
+	order models.Order,
+	note string,
+) (bool, error) {
+	var (
+		reason   string
+		released bool
+		err      error
+	)
+	switch order.Status {
+	case models.OrderStatusUnpaid:
+		reason = models.StockMovementReasonCancellation
+		released, err = orderRepo.CancelUnpaidOrder(ctx, order)
+	case models.OrderStatusPaid:
+		reason = models.StockMovementReasonRefund
+		released, err = orderRepo.RefundPaidOrder(ctx, order)
+	default:
+		return false, nil
+	}
+	if err != nil || !released {
+		return false, err
+	}
+
+	if err := inventoryUsecase.ReturnOrderStock(ctx, order.ID, reason, note); err != nil {
+		return false, err
 	}
-	err = u.orderRepo.DeleteOrder(order)
-	return err
+	return true, nil
+}
+
+// orderHoldsStock reports whether the order still holds the stock it took
+// at checkout.
+func orderHoldsStock(order models.Order) bool {
+	return order.Status == models.OrderStatusUnpaid || order.Status == models.OrderStatusPaid
+}
+
+// ExpireOrders cancels the orders still unpaid olderThan after checkout and
+// returns their stock. It returns the IDs of the cancelled orders. Each order
+// is cancelled and its stock returned in one transaction, so an order is
+// either cancelled with its stock back or left unpaid, and running it again
+// never returns the stock of an order twice.
+func (u *orderUsecase) ExpireOrders(ctx context.Context, olderThan time.Duration) ([]uint, error) {
+	orders, err := u.orderRepo.GetUnpaidOrdersCreatedBefore(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []uint{}
+	for _, order := range orders {
+		var cancelled bool
+		err = u.transactor.Transaction(ctx, func(ctx context.Context) error {
+			cancelled, err = releaseOrderStock(ctx, u.orderRepo, u.inventoryUsecase, order, "Unpaid order expired")
+			return err
+		})
+		if err != nil {
+			return expired, err
+		}
+		if cancelled {
+			expired = append(expired, order.ID)
+			u.log.InfoContext(ctx, "unpaid order expired", slog.Uint64("order_id", uint64(order.ID)))
+		}
+	}
+
+	return expired, nil
 }
@@ -1,17 +1,18 @@
 package usecases
 
 import (
+	"context"
 	"synapsis-backend/dtos"
 	"synapsis-backend/models"
 	"synapsis-backend/repositories"
 )
 
 type OrderDetailUsecase interface {
-	GetAllOrderDetails(page, limit, user_id int) ([]dtos.OrderDetailResponse, int, error)
-	GetOrderDetailByID(id uint) (dtos.OrderDetailResponse, error)
-	CreateOrderDetail(orderDetail *dtos.OrderDetailInput) (dtos.OrderDetailResponse, error)
-	UpdateOrderDetail(id uint, orderDetailInput dtos.OrderDetailInput) (dtos.OrderDetailResponse, error)
-	DeleteOrderDetail(id uint) error
+	GetAllOrderDetails(ctx context.Context, page, limit, user_id int) ([]dtos.OrderDetailResponse, int, error)
+	GetOrderDetailByID(ctx context.Context, id uint) (dtos.OrderDetailResponse, error)
+	CreateOrderDetail(ctx context.Context, orderDetail *dtos.OrderDetailInput) (dtos.OrderDetailResponse, error)
+	UpdateOrderDetail(ctx context.Context, id uint, orderDetailInput dtos.OrderDetailInput) (dtos.OrderDetailResponse, error)
+	DeleteOrderDetail(ctx context.Context, id uint) error
 }
 
 type orderDetailUsecase struct {
@@ -39,15 +40,15 @@ func NewOrderDetailUsecase(OrderDetailRepo repositories.OrderDetailRepository) O
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /orderDetail [get]
 // @Security BearerAuth
-func (u *orderDetailUsecase) GetAllOrderDetails(page, limit, user_id int) ([]dtos.OrderDetailResponse, int, error) {
-	orderDetails, count, err := u.orderDetailRepo.GetAllOrderDetails(page, limit, user_id)
+func (u *orderDetailUsecase) GetAllOrderDetails(ctx context.Context, page, limit, user_id int) ([]dtos.OrderDetailResponse, int, error) {
+	orderDetails, count, err := u.orderDetailRepo.GetAllOrderDetails(ctx, page, limit, user_id)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	var orderDetailResponses []dtos.OrderDetailResponse
 	for _, orderDetail := range orderDetails {
-		// category, err := u.orderDetailRepo.GetCategoryByID(orderDetail.CategoryID)
+		// category, err := u.orderDetailRepo.GetCategoryByID(ctx, orderDetail.CategoryID)
 
 		orderDetailResponse := dtos.OrderDetailResponse{
 			OrderDetailID: orderDetail.ID,
@@ -80,9 +81,9 @@ func (u *orderDetailUsecase) GetAllOrderDetails(page, limit, user_id int) ([]dto
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /orderDetail/{id} [get]
 // @Security BearerAuth
-func (u *orderDetailUsecase) GetOrderDetailByID(id uint) (dtos.OrderDetailResponse, error) {
+func (u *orderDetailUsecase) GetOrderDetailByID(ctx context.Context, id uint) (dtos.OrderDetailResponse, error) {
 	var orderDetailResponses dtos.OrderDetailResponse
-	orderDetail, err := u.orderDetailRepo.GetOrderDetailByID(id)
+	orderDetail, err := u.orderDetailRepo.GetOrderDetailByID(ctx, id)
 	if err != nil {
 		return orderDetailResponses, err
 	}
@@ -108,13 +109,14 @@ func (u *orderDetailUsecase) GetOrderDetailByID(id uint) (dtos.OrderDetailRespon
 // @Param        request body dtos.OrderDetailInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.OrderDetailStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /orderDetail [post]
 // @Security BearerAuth
-func (u *orderDetailUsecase) CreateOrderDetail(orderDetail *dtos.OrderDetailInput) (dtos.OrderDetailResponse, error) {
+func (u *orderDetailUsecase) CreateOrderDetail(ctx context.Context, orderDetail *dtos.OrderDetailInput) (dtos.OrderDetailResponse, error) {
 	var orderDetailResponses dtos.OrderDetailResponse
 
 	createOrderDetail := models.OrderDetail{
@@ -125,7 +127,7 @@ func (u *orderDetailUsecase) CreateOrderDetail(orderDetail *dtos.OrderDetailInpu
 		Discount:  orderDetail.Discount,
 	}
 
-	createdOrderDetail, err := u.orderDetailRepo.CreateOrderDetail(createOrderDetail)
+	createdOrderDetail, err := u.orderDetailRepo.CreateOrderDetail(ctx, createOrderDetail)
 	if err != nil {
 		return orderDetailResponses, err
 	}
@@ -154,18 +156,19 @@ func (u *orderDetailUsecase) CreateOrderDetail(orderDetail *dtos.OrderDetailInpu
 // @Param        request body dtos.OrderDetailInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.OrderDetailStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /orderDetail/{id} [put]
 // @Security BearerAuth
-func (u *orderDetailUsecase) UpdateOrderDetail(id uint, orderDetailInput dtos.OrderDetailInput) (dtos.OrderDetailResponse, error) {
+func (u *orderDetailUsecase) UpdateOrderDetail(ctx context.Context, id uint, orderDetailInput dtos.OrderDetailInput) (dtos.OrderDetailResponse, error) {
 
 	var orderDetail models.OrderDetail
 	var orderDetailResponse dtos.OrderDetailResponse
 
-	orderDetail, err := u.orderDetailRepo.GetOrderDetailByID(id)
+	orderDetail, err := u.orderDetailRepo.GetOrderDetailByID(ctx, id)
 	if err != nil {
 		return orderDetailResponse, err
 	}
@@ -177,7 +180,7 @@ func (u *orderDetailUsecase) UpdateOrderDetail(id uint, orderDetailInput dtos.Or
 	orderDetail.SubTotal = orderDetailInput.SubTotal
 	orderDetail.Discount = orderDetailInput.Discount
 
-	orderDetail, err = u.orderDetailRepo.UpdateOrderDetail(orderDetail)
+	orderDetail, err = u.orderDetailRepo.UpdateOrderDetail(ctx, orderDetail)
 
 	if err != nil {
 		return orderDetailResponse, err
@@ -211,12 +214,12 @@ func (u *orderDetailUsecase) UpdateOrderDetail(id uint, orderDetailInput dtos.Or
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /orderDetail/{id} [delete]
 // @Security BearerAuth
-func (u *orderDetailUsecase) DeleteOrderDetail(id uint) error {
-	orderDetail, err := u.orderDetailRepo.GetOrderDetailByID(id)
+func (u *orderDetailUsecase) DeleteOrderDetail(ctx context.Context, id uint) error {
+	orderDetail, err := u.orderDetailRepo.GetOrderDetailByID(ctx, id)
 
 	if err != nil {
-		return nil
+		return err
 	}
-	err = u.orderDetailRepo.DeleteOrderDetail(orderDetail)
+	err = u.orderDetailRepo.DeleteOrderDetail(ctx, orderDetail)
 	return err
 }
@@ -1,30 +1,45 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"synapsis-backend/apperrors"
 	"synapsis-backend/dtos"
+	"synapsis-backend/metrics"
 	"synapsis-backend/models"
 	"synapsis-backend/repositories"
+
+	"gorm.io/gorm"
 )
 
 type PaymentUsecase interface {
-	GetAllPayments(page, limit, user_id int) ([]dtos.PaymentResponse, int, error)
-	GetPaymentByID(id uint) (dtos.PaymentResponse, error)
-	CreatePayment(payment *dtos.PaymentInput) (dtos.PaymentResponse, error)
-	UpdatePayment(id uint, paymentInput dtos.PaymentInput) (dtos.PaymentResponse, error)
-	DeletePayment(id uint) error
+	GetAllPayments(ctx context.Context, page, limit, user_id int) ([]dtos.PaymentResponse, int, error)
+	GetPaymentByID(ctx context.Context, id uint) (dtos.PaymentResponse, error)
+	GetPaymentsByUserID(ctx context.Context, page, limit int, userID uint) ([]dtos.PaymentResponse, int, error)
+	CreatePayment(ctx context.Context, payment *dtos.PaymentInput) (dtos.PaymentResponse, error)
+	UpdatePayment(ctx context.Context, id uint, paymentInput dtos.PaymentInput) (dtos.PaymentResponse, error)
+	DeletePayment(ctx context.Context, id uint) error
 }
 
 type paymentUsecase struct {
-	paymentRepo repositories.PaymentRepository
-	orderRepo   repositories.OrderRepository
+	paymentRepo      repositories.PaymentRepository
+	orderRepo        repositories.OrderRepository
+	userRepo         repositories.UserRepository
+	transactor       repositories.Transactor
+	inventoryUsecase InventoryUsecase
+	log              *slog.Logger
 }
 
 func NewPaymentUsecase(
 	PaymentRepo repositories.PaymentRepository,
 	OrderRepo repositories.OrderRepository,
+	UserRepo repositories.UserRepository,
+	Transactor repositories.Transactor,
+	InventoryUsecase InventoryUsecase,
+	Log *slog.Logger,
 ) PaymentUsecase {
-	return &paymentUsecase{PaymentRepo, OrderRepo}
+	return &paymentUsecase{PaymentRepo, OrderRepo, UserRepo, Transactor, InventoryUsecase, Log}
 }
 
 // GetAllPayments godoc
@@ -44,15 +59,15 @@ func NewPaymentUsecase(
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /payment [get]
 // @Security BearerAuth
-func (u *paymentUsecase) GetAllPayments(page, limit, user_id int) ([]dtos.PaymentResponse, int, error) {
-	payments, count, err := u.paymentRepo.GetAllPayments(page, limit, user_id)
+func (u *paymentUsecase) GetAllPayments(ctx context.Context, page, limit, user_id int) ([]dtos.PaymentResponse, int, error) {
+	payments, count, err := u.paymentRepo.GetAllPayments(ctx, page, limit, user_id)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	var paymentResponses []dtos.PaymentResponse
 	for _, payment := range payments {
-		// category, err := u.paymentRepo.GetCategoryByID(payment.CategoryID)
+		// category, err := u.paymentRepo.GetCategoryByID(ctx, payment.CategoryID)
 
 		paymentResponse := dtos.PaymentResponse{
 			PaymentID:   payment.ID,
@@ -69,6 +84,45 @@ func (u *paymentUsecase) GetAllPayments(page, limit, user_id int) ([]dtos.Paymen
 	return paymentResponses, count, nil
 }
 
+// GetPaymentsByUserID godoc
+// @Summary      Get payments of a user
+// @Description  Get the payments of a user, newest first
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success      200 {object} dtos.GetAllPaymentStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id}/payments [get]
+// @Security BearerAuth
+func (u *paymentUsecase) GetPaymentsByUserID(ctx context.Context, page, limit int, userID uint) ([]dtos.PaymentResponse, int, error) {
+	payments, count, err := u.paymentRepo.GetPaymentsByUserID(ctx, page, limit, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	paymentResponses := []dtos.PaymentResponse{}
+	for _, payment := range payments {
+		paymentResponses = append(paymentResponses, dtos.PaymentResponse{
+			PaymentID:   payment.ID,
+			OrderID:     payment.OrderID,
+			UserID:      payment.UserID,
+			PaymentType: payment.PaymentType,
+			Amount:      payment.Amount,
+			CreatedAt:   payment.CreatedAt,
+			UpdatedAt:   payment.UpdatedAt,
+		})
+	}
+
+	return paymentResponses, count, nil
+}
+
 // GetPaymentByID godoc
 // @Summary      Get payment by ID
 // @Description  Get payment by ID
@@ -84,9 +138,9 @@ func (u *paymentUsecase) GetAllPayments(page, limit, user_id int) ([]dtos.Paymen
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /payment/{id} [get]
 // @Security BearerAuth
-func (u *paymentUsecase) GetPaymentByID(id uint) (dtos.PaymentResponse, error) {
+func (u *paymentUsecase) GetPaymentByID(ctx context.Context, id uint) (dtos.PaymentResponse, error) {
 	var paymentResponses dtos.PaymentResponse
-	payment, err := u.paymentRepo.GetPaymentByID(id)
+	payment, err := u.paymentRepo.GetPaymentByID(ctx, id)
 	if err != nil {
 		return paymentResponses, err
 	}
@@ -111,13 +165,15 @@ func (u *paymentUsecase) GetPaymentByID(id uint) (dtos.PaymentResponse, error) {
 // @Param        request body dtos.PaymentInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.PaymentStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /payment [post]
 // @Security BearerAuth
-func (u *paymentUsecase) CreatePayment(payment *dtos.PaymentInput) (dtos.PaymentResponse, error) {
+func (u *paymentUsecase) CreatePayment(ctx context.Context, payment *dtos.PaymentInput) (dtos.PaymentResponse, error) {
 	var paymentResponses dtos.PaymentResponse
 
 	createPayment := models.Payment{
@@ -127,29 +183,54 @@ func (u *paymentUsecase) CreatePayment(payment *dtos.PaymentInput) (dtos.Payment
 		Amount:      payment.Amount,
 	}
 
-	order, err := u.orderRepo.GetOrderByID(createPayment.OrderID)
+	order, err := u.orderRepo.GetOrderByID(ctx, createPayment.OrderID)
 	if err != nil {
 		return paymentResponses, err
 	}
 
+	if order.UserID != createPayment.UserID {
+		return paymentResponses, apperrors.Forbidden("Order %d belongs to another user", order.ID)
+	}
+
+	if err := ensureEmailVerified(ctx, u.userRepo, createPayment.UserID); err != nil {
+		return paymentResponses, err
+	}
+
+	if order.Status != models.OrderStatusUnpaid {
+		return paymentResponses, apperrors.Conflict("Order is %s", order.Status)
+	}
+
 	// if Amount Money in Payment < order.TotalPrice
 	if createPayment.Amount < order.TotalPrice {
 		return paymentResponses, errors.New("Amount Money in Payment < order.TotalPrice")
 	}
 
-	// Update Status Order to Paid
-
-	order.Status = "paid"
-	order, err = u.orderRepo.UpdateOrder(order)
+	// The order is only paid while it is still unpaid, so it cannot be paid
+	// twice or after it expired, and the payment is created with it.
+	var createdPayment models.Payment
+	err = u.transactor.Transaction(ctx, func(ctx context.Context) error {
+		paid, err := u.orderRepo.PayUnpaidOrder(ctx, order)
+		if err != nil {
+			return err
+		}
+		if !paid {
+			return apperrors.Conflict("Order %d is no longer unpaid", order.ID)
+		}
 
+		createdPayment, err = u.paymentRepo.CreatePayment(ctx, createPayment)
+		return err
+	})
 	if err != nil {
 		return paymentResponses, err
 	}
 
-	createdPayment, err := u.paymentRepo.CreatePayment(createPayment)
-	if err != nil {
-		return paymentResponses, err
-	}
+	metrics.PaymentsCaptured.Inc()
+	metrics.Revenue.Add(float64(order.TotalPrice))
+	u.log.InfoContext(ctx, "payment captured",
+		slog.Uint64("payment_id", uint64(createdPayment.ID)),
+		slog.Uint64("order_id", uint64(order.ID)),
+		slog.Int("amount", createdPayment.Amount),
+	)
 
 	paymentResponse := dtos.PaymentResponse{
 		PaymentID:   createdPayment.ID,
@@ -174,18 +255,19 @@ func (u *paymentUsecase) CreatePayment(payment *dtos.PaymentInput) (dtos.Payment
 // @Param        request body dtos.PaymentInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.PaymentStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /payment/{id} [put]
 // @Security BearerAuth
-func (u *paymentUsecase) UpdatePayment(id uint, paymentInput dtos.PaymentInput) (dtos.PaymentResponse, error) {
+func (u *paymentUsecase) UpdatePayment(ctx context.Context, id uint, paymentInput dtos.PaymentInput) (dtos.PaymentResponse, error) {
 
 	var payment models.Payment
 	var paymentResponse dtos.PaymentResponse
 
-	payment, err := u.paymentRepo.GetPaymentByID(id)
+	payment, err := u.paymentRepo.GetPaymentByID(ctx, id)
 	if err != nil {
 		return paymentResponse, err
 	}
@@ -196,7 +278,7 @@ func (u *paymentUsecase) UpdatePayment(id uint, paymentInput dtos.PaymentInput)
 	payment.PaymentType = paymentInput.PaymentType
 	payment.Amount = paymentInput.Amount
 
-	payment, err = u.paymentRepo.UpdatePayment(payment)
+	payment, err = u.paymentRepo.UpdatePayment(ctx, payment)
 
 	if err != nil {
 		return paymentResponse, err
@@ -216,7 +298,7 @@ func (u *paymentUsecase) UpdatePayment(id uint, paymentInput dtos.PaymentInput)
 
 // DeletePayment godoc
 // @Summary      Delete a payment
-// @Description  Delete a payment
+// @Description  Delete a payment. The order of the payment, when paid, is refunded and its stock returned.
 // @Tags         Payment
 // @Accept       json
 // @Produce      json
@@ -226,15 +308,42 @@ func (u *paymentUsecase) UpdatePayment(id uint, paymentInput dtos.PaymentInput)
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /payment/{id} [delete]
 // @Security BearerAuth
-func (u *paymentUsecase) DeletePayment(id uint) error {
-	payment, err := u.paymentRepo.GetPaymentByID(id)
+func (u *paymentUsecase) DeletePayment(ctx context.Context, id uint) error {
+	payment, err := u.paymentRepo.GetPaymentByID(ctx, id)
 
 	if err != nil {
-		return nil
+		return err
+	}
+	order, err := u.orderRepo.GetOrderByID(ctx, payment.OrderID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	// Deleting the payment of a paid order refunds the order and returns its
+	// stock.
+	err = u.transactor.Transaction(ctx, func(ctx context.Context) error {
+		if order.Status == models.OrderStatusPaid {
+			released, err := releaseOrderStock(ctx, u.orderRepo, u.inventoryUsecase, order, "Payment deleted")
+			if err != nil {
+				return err
+			}
+			if !released {
+				return apperrors.Conflict("Order %d was changed, try again", order.ID)
+			}
+		}
+		return u.paymentRepo.DeletePayment(ctx, payment)
+	})
+	if err != nil {
+		return err
 	}
-	err = u.paymentRepo.DeletePayment(payment)
-	return err
+	u.log.InfoContext(ctx, "payment deleted",
+		slog.Uint64("payment_id", uint64(payment.ID)),
+		slog.Uint64("order_id", uint64(payment.OrderID)),
+		slog.Bool("refunded", order.Status == models.OrderStatusPaid),
+	)
+	return nil
 }
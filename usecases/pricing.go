@@ -0,0 +1,48 @@
+package usecases
+
+const (
+	// Lines with at least bulkDiscountMinQuantity items get bulkDiscountPercent off.
+	bulkDiscountMinQuantity = 10
+	bulkDiscountPercent     = 5
+	// PPN charged on the discounted subtotal.
+	taxPercent = 11
+)
+
+// linePrice is the price of a cart line.
+type linePrice struct {
+	SubTotal int
+	Discount int
+	Total    int
+}
+
+// priceLine prices quantity items of a product at unitPrice. The cart summary
+// and checkout both price a cart with priceLine and priceCart, so the summary
+// shows what checkout charges.
+func priceLine(unitPrice, quantity int) linePrice {
+	line := linePrice{SubTotal: unitPrice * quantity}
+	if quantity >= bulkDiscountMinQuantity {
+		line.Discount = line.SubTotal * bulkDiscountPercent / 100
+	}
+	line.Total = line.SubTotal - line.Discount
+	return line
+}
+
+// cartPrice is the price of a whole cart.
+type cartPrice struct {
+	SubTotal   int
+	Discount   int
+	Tax        int
+	GrandTotal int
+}
+
+// priceCart adds up the lines and the tax on them.
+func priceCart(lines []linePrice) cartPrice {
+	var cart cartPrice
+	for _, line := range lines {
+		cart.SubTotal += line.SubTotal
+		cart.Discount += line.Discount
+	}
+	cart.Tax = (cart.SubTotal - cart.Discount) * taxPercent / 100
+	cart.GrandTotal = cart.SubTotal - cart.Discount + cart.Tax
+	return cart
+}
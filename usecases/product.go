@@ -1,25 +1,34 @@
 package usecases
 
 import (
+	"context"
 	"synapsis-backend/dtos"
 	"synapsis-backend/models"
 	"synapsis-backend/repositories"
 )
 
 type ProductUsecase interface {
-	GetAllProducts(page, limit, category_id int) ([]dtos.ProductResponse, int, error)
-	GetProductByID(id uint) (dtos.ProductResponse, error)
-	CreateProduct(product *dtos.ProductInput) (dtos.ProductResponse, error)
-	UpdateProduct(id uint, productInput dtos.ProductInput) (dtos.ProductResponse, error)
-	DeleteProduct(id uint) error
+	GetAllProducts(ctx context.Context, page, limit, category_id int) ([]dtos.ProductResponse, int, error)
+	GetProductByID(ctx context.Context, id uint) (dtos.ProductResponse, error)
+	CreateProduct(ctx context.Context, product *dtos.ProductInput) (dtos.ProductResponse, error)
+	UpdateProduct(ctx context.Context, id uint, productInput dtos.ProductInput) (dtos.ProductResponse, error)
+	DeleteProduct(ctx context.Context, id uint) error
 }
 
 type productUsecase struct {
-	productRepo repositories.ProductRepository
+	productRepo      repositories.ProductRepository
+	reviewRepo       repositories.ReviewRepository
+	transactor       repositories.Transactor
+	inventoryUsecase InventoryUsecase
 }
 
-func NewProductUsecase(ProductRepo repositories.ProductRepository) ProductUsecase {
-	return &productUsecase{ProductRepo}
+func NewProductUsecase(
+	ProductRepo repositories.ProductRepository,
+	ReviewRepo repositories.ReviewRepository,
+	Transactor repositories.Transactor,
+	InventoryUsecase InventoryUsecase,
+) ProductUsecase {
+	return &productUsecase{ProductRepo, ReviewRepo, Transactor, InventoryUsecase}
 }
 
 // GetAllProducts godoc
@@ -39,26 +48,38 @@ func NewProductUsecase(ProductRepo repositories.ProductRepository) ProductUsecas
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /product [get]
 // @Security BearerAuth
-func (u *productUsecase) GetAllProducts(page, limit, category_id int) ([]dtos.ProductResponse, int, error) {
-	products, count, err := u.productRepo.GetAllProducts(page, limit, category_id)
+func (u *productUsecase) GetAllProducts(ctx context.Context, page, limit, category_id int) ([]dtos.ProductResponse, int, error) {
+	products, count, err := u.productRepo.GetAllProducts(ctx, page, limit, category_id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	productIDs := make([]uint, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+	ratings, err := u.reviewRepo.GetProductRatings(ctx, productIDs)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	var productResponses []dtos.ProductResponse
 	for _, product := range products {
-		// category, err := u.productRepo.GetCategoryByID(product.CategoryID)
+		// category, err := u.productRepo.GetCategoryByID(ctx, product.CategoryID)
 
 		productResponse := dtos.ProductResponse{
-			ProductID:   product.ID,
-			CategoryID:  product.CategoryID,
-			Name:        product.Name,
-			Price:       product.Price,
-			Description: product.Description,
-			Stock:       product.Stock,
-			Status:      product.Status,
-			CreatedAt:   product.CreatedAt,
-			UpdatedAt:   product.UpdatedAt,
+			ProductID:        product.ID,
+			CategoryID:       product.CategoryID,
+			Name:             product.Name,
+			Price:            product.Price,
+			Description:      product.Description,
+			Stock:            product.Stock,
+			ReorderThreshold: product.ReorderThreshold,
+			Status:           product.Status,
+			AverageRating:    ratings[product.ID].AverageRating,
+			ReviewCount:      ratings[product.ID].ReviewCount,
+			CreatedAt:        product.CreatedAt,
+			UpdatedAt:        product.UpdatedAt,
 		}
 		productResponses = append(productResponses, productResponse)
 	}
@@ -81,22 +102,29 @@ func (u *productUsecase) GetAllProducts(page, limit, category_id int) ([]dtos.Pr
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /product/{id} [get]
 // @Security BearerAuth
-func (u *productUsecase) GetProductByID(id uint) (dtos.ProductResponse, error) {
+func (u *productUsecase) GetProductByID(ctx context.Context, id uint) (dtos.ProductResponse, error) {
 	var productResponses dtos.ProductResponse
-	product, err := u.productRepo.GetProductByID(id)
+	product, err := u.productRepo.GetProductByID(ctx, id)
+	if err != nil {
+		return productResponses, err
+	}
+	ratings, err := u.reviewRepo.GetProductRatings(ctx, []uint{product.ID})
 	if err != nil {
 		return productResponses, err
 	}
 	productResponse := dtos.ProductResponse{
-		ProductID:   product.ID,
-		CategoryID:  product.CategoryID,
-		Name:        product.Name,
-		Price:       product.Price,
-		Description: product.Description,
-		Stock:       product.Stock,
-		Status:      product.Status,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+		ProductID:        product.ID,
+		CategoryID:       product.CategoryID,
+		Name:             product.Name,
+		Price:            product.Price,
+		Description:      product.Description,
+		Stock:            product.Stock,
+		ReorderThreshold: product.ReorderThreshold,
+		Status:           product.Status,
+		AverageRating:    ratings[product.ID].AverageRating,
+		ReviewCount:      ratings[product.ID].ReviewCount,
+		CreatedAt:        product.CreatedAt,
+		UpdatedAt:        product.UpdatedAt,
 	}
 	return productResponse, nil
 }
@@ -110,38 +138,55 @@ func (u *productUsecase) GetProductByID(id uint) (dtos.ProductResponse, error) {
 // @Param        request body dtos.ProductInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.ProductStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /product [post]
 // @Security BearerAuth
-func (u *productUsecase) CreateProduct(product *dtos.ProductInput) (dtos.ProductResponse, error) {
+func (u *productUsecase) CreateProduct(ctx context.Context, product *dtos.ProductInput) (dtos.ProductResponse, error) {
 	var productResponses dtos.ProductResponse
 
 	createProduct := models.Product{
-		CategoryID:  product.CategoryID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		Status:      product.Status,
+		CategoryID:       product.CategoryID,
+		Name:             product.Name,
+		Description:      product.Description,
+		Price:            product.Price,
+		Status:           product.Status,
+		ReorderThreshold: product.ReorderThreshold,
 	}
 
-	createdProduct, err := u.productRepo.CreateProduct(createProduct)
+	createdProduct, err := u.productRepo.CreateProduct(ctx, createProduct)
 	if err != nil {
 		return productResponses, err
 	}
 
+	if product.Stock != 0 {
+		movement, err := u.inventoryUsecase.RecordStockMovement(ctx, models.StockMovement{
+			ProductID: createdProduct.ID,
+			Reason:    models.StockMovementReasonRestock,
+			Quantity:  product.Stock,
+			Note:      "Initial stock",
+		})
+		if err != nil {
+			return productResponses, err
+		}
+		createdProduct.Stock = movement.StockAfter
+	} else if err := u.inventoryUsecase.CheckStockLevel(ctx, createdProduct.ID); err != nil {
+		return productResponses, err
+	}
+
 	productResponse := dtos.ProductResponse{
-		ProductID:  createdProduct.ID,
-		CategoryID: createdProduct.CategoryID,
-		Name:       createdProduct.Name,
-		Price:      createdProduct.Price,
-		Stock:      createdProduct.Stock,
-		Status:     createdProduct.Status,
-		CreatedAt:  createdProduct.CreatedAt,
-		UpdatedAt:  createdProduct.UpdatedAt,
+		ProductID:        createdProduct.ID,
+		CategoryID:       createdProduct.CategoryID,
+		Name:             createdProduct.Name,
+		Price:            createdProduct.Price,
+		Stock:            createdProduct.Stock,
+		ReorderThreshold: createdProduct.ReorderThreshold,
+		Status:           createdProduct.Status,
+		CreatedAt:        createdProduct.CreatedAt,
+		UpdatedAt:        createdProduct.UpdatedAt,
 	}
 
 	return productResponse, nil
@@ -157,18 +202,19 @@ func (u *productUsecase) CreateProduct(product *dtos.ProductInput) (dtos.Product
 // @Param        request body dtos.ProductInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.ProductStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /product/{id} [put]
 // @Security BearerAuth
-func (u *productUsecase) UpdateProduct(id uint, productInput dtos.ProductInput) (dtos.ProductResponse, error) {
+func (u *productUsecase) UpdateProduct(ctx context.Context, id uint, productInput dtos.ProductInput) (dtos.ProductResponse, error) {
 
 	var product models.Product
 	var productResponse dtos.ProductResponse
 
-	product, err := u.productRepo.GetProductByID(id)
+	product, err := u.productRepo.GetProductByID(ctx, id)
 	if err != nil {
 		return productResponse, err
 	}
@@ -178,14 +224,28 @@ func (u *productUsecase) UpdateProduct(id uint, productInput dtos.ProductInput)
 	product.Name = productInput.Name
 	product.Description = productInput.Description
 	product.Price = productInput.Price
-	product.Stock = productInput.Stock
+	product.ReorderThreshold = productInput.ReorderThreshold
 	product.Status = productInput.Status
 
-	product, err = u.productRepo.UpdateProduct(product)
+	// The stock is set to the value entered, whatever it is when the update
+	// runs, in the same transaction as the other fields.
+	err = u.transactor.Transaction(ctx, func(ctx context.Context) error {
+		product, err = u.productRepo.UpdateProduct(ctx, product)
+		if err != nil {
+			return err
+		}
 
+		_, err = u.inventoryUsecase.SetStockLevel(ctx, models.StockMovement{
+			ProductID: product.ID,
+			Reason:    models.StockMovementReasonAdjustment,
+			Note:      "Stock updated through product update",
+		}, productInput.Stock)
+		return err
+	})
 	if err != nil {
 		return productResponse, err
 	}
+	product.Stock = productInput.Stock
 
 	productResponse.ProductID = product.ID
 	productResponse.CategoryID = product.CategoryID
@@ -193,6 +253,7 @@ func (u *productUsecase) UpdateProduct(id uint, productInput dtos.ProductInput)
 	productResponse.Description = product.Description
 	productResponse.Price = product.Price
 	productResponse.Stock = product.Stock
+	productResponse.ReorderThreshold = product.ReorderThreshold
 	productResponse.Status = product.Status
 	productResponse.CreatedAt = product.CreatedAt
 	productResponse.UpdatedAt = product.UpdatedAt
@@ -216,12 +277,12 @@ func (u *productUsecase) UpdateProduct(id uint, productInput dtos.ProductInput)
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /product/{id} [delete]
 // @Security BearerAuth
-func (u *productUsecase) DeleteProduct(id uint) error {
-	product, err := u.productRepo.GetProductByID(id)
+func (u *productUsecase) DeleteProduct(ctx context.Context, id uint) error {
+	product, err := u.productRepo.GetProductByID(ctx, id)
 
 	if err != nil {
-		return nil
+		return err
 	}
-	err = u.productRepo.DeleteProduct(product)
+	err = u.productRepo.DeleteProduct(ctx, product)
 	return err
 }
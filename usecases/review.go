@@ -0,0 +1,281 @@
+package usecases
+
+import (
+	"context"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/models"
+	"synapsis-backend/repositories"
+)
+
+// Orders in these statuses count as a purchase when checking whether a user
+// may review a product.
+var reviewableOrderStatuses = []string{models.OrderStatusPaid, models.OrderStatusDelivered}
+
+type ReviewUsecase interface {
+	GetProductReviews(ctx context.Context, page, limit int, productID uint) ([]dtos.ReviewResponse, int, error)
+	CreateReview(ctx context.Context, userID, productID uint, input dtos.ReviewInput) (dtos.ReviewResponse, error)
+	UpdateReview(ctx context.Context, userID, productID uint, input dtos.ReviewInput) (dtos.ReviewResponse, error)
+	GetAllReviews(ctx context.Context, page, limit int, productID uint, status string) ([]dtos.ReviewResponse, int, error)
+	HideReview(ctx context.Context, id uint, input dtos.ReviewHideInput) (dtos.ReviewResponse, error)
+	UnhideReview(ctx context.Context, id uint) (dtos.ReviewResponse, error)
+}
+
+type reviewUsecase struct {
+	reviewRepo        repositories.ReviewRepository
+	productRepo       repositories.ProductRepository
+	stockMovementRepo repositories.StockMovementRepository
+}
+
+func NewReviewUsecase(
+	ReviewRepo repositories.ReviewRepository,
+	ProductRepo repositories.ProductRepository,
+	StockMovementRepo repositories.StockMovementRepository,
+) ReviewUsecase {
+	return &reviewUsecase{ReviewRepo, ProductRepo, StockMovementRepo}
+}
+
+// GetProductReviews godoc
+// @Summary      Get product reviews
+// @Description  Get the visible reviews of a product
+// @Tags         Review
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID product"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success      200 {object} dtos.GetAllReviewStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /product/{id}/reviews [get]
+// @Security BearerAuth
+func (u *reviewUsecase) GetProductReviews(ctx context.Context, page, limit int, productID uint) ([]dtos.ReviewResponse, int, error) {
+	hidden := false
+	reviews, count, err := u.reviewRepo.GetAllReviews(ctx, page, limit, productID, &hidden)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reviewResponses := []dtos.ReviewResponse{}
+	for _, review := range reviews {
+		reviewResponses = append(reviewResponses, toReviewResponse(review))
+	}
+
+	return reviewResponses, count, nil
+}
+
+// CreateReview godoc
+// @Summary      Review a product
+// @Description  Review a product. Only users with a paid or delivered order for the product may review it
+// @Tags         Review
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID product"
+// @Param        request body dtos.ReviewInput true "Payload Body [RAW]"
+// @Success      201 {object} dtos.ReviewCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /product/{id}/reviews [post]
+// @Security BearerAuth
+func (u *reviewUsecase) CreateReview(ctx context.Context, userID, productID uint, input dtos.ReviewInput) (dtos.ReviewResponse, error) {
+	var reviewResponse dtos.ReviewResponse
+
+	if input.Rating < 1 || input.Rating > 5 {
+		return reviewResponse, apperrors.Validation("Rating must be between 1 and 5")
+	}
+
+	if _, err := u.productRepo.GetProductByID(ctx, productID); err != nil {
+		return reviewResponse, apperrors.NotFound("Product not found")
+	}
+
+	purchased, err := u.stockMovementRepo.HasPurchasedProduct(ctx, userID, productID, reviewableOrderStatuses)
+	if err != nil {
+		return reviewResponse, err
+	}
+	if !purchased {
+		return reviewResponse, apperrors.Forbidden("Only customers who bought this product can review it")
+	}
+
+	existing, err := u.reviewRepo.GetReviewByUserAndProduct(ctx, userID, productID)
+	if err == nil && existing.ID > 0 {
+		return reviewResponse, apperrors.Conflict("Product already reviewed")
+	}
+
+	review, err := u.reviewRepo.CreateReview(ctx, models.Review{
+		UserID:    userID,
+		ProductID: productID,
+		Rating:    input.Rating,
+		Comment:   input.Comment,
+	})
+	if err != nil {
+		return reviewResponse, err
+	}
+
+	return toReviewResponse(review), nil
+}
+
+// UpdateReview godoc
+// @Summary      Update review
+// @Description  Update the review the logged in user wrote for a product
+// @Tags         Review
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID product"
+// @Param        request body dtos.ReviewInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.ReviewStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /product/{id}/reviews [put]
+// @Security BearerAuth
+func (u *reviewUsecase) UpdateReview(ctx context.Context, userID, productID uint, input dtos.ReviewInput) (dtos.ReviewResponse, error) {
+	var reviewResponse dtos.ReviewResponse
+
+	if input.Rating < 1 || input.Rating > 5 {
+		return reviewResponse, apperrors.Validation("Rating must be between 1 and 5")
+	}
+
+	review, err := u.reviewRepo.GetReviewByUserAndProduct(ctx, userID, productID)
+	if err != nil {
+		return reviewResponse, apperrors.NotFound("Review not found")
+	}
+
+	review.Rating = input.Rating
+	review.Comment = input.Comment
+
+	review, err = u.reviewRepo.UpdateReview(ctx, review)
+	if err != nil {
+		return reviewResponse, err
+	}
+
+	return toReviewResponse(review), nil
+}
+
+// GetAllReviews godoc
+// @Summary      Get all review
+// @Description  Get all reviews for moderation
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param product_id query int false "Search by product ID"
+// @Param status query string false "Search by status like 'visible' or 'hidden'"
+// @Success      200 {object} dtos.GetAllReviewStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/reviews [get]
+// @Security BearerAuth
+func (u *reviewUsecase) GetAllReviews(ctx context.Context, page, limit int, productID uint, status string) ([]dtos.ReviewResponse, int, error) {
+	var hidden *bool
+	switch status {
+	case "":
+	case "visible":
+		hidden = new(bool)
+	case "hidden":
+		hidden = new(bool)
+		*hidden = true
+	default:
+		return nil, 0, apperrors.Validation("Status must be 'visible' or 'hidden'")
+	}
+
+	reviews, count, err := u.reviewRepo.GetAllReviews(ctx, page, limit, productID, hidden)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reviewResponses := []dtos.ReviewResponse{}
+	for _, review := range reviews {
+		reviewResponses = append(reviewResponses, toReviewResponse(review))
+	}
+
+	return reviewResponses, count, nil
+}
+
+// HideReview godoc
+// @Summary      Hide review
+// @Description  Hide a review from the product page
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID review"
+// @Param        request body dtos.ReviewHideInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.ReviewStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/reviews/{id}/hide [put]
+// @Security BearerAuth
+func (u *reviewUsecase) HideReview(ctx context.Context, id uint, input dtos.ReviewHideInput) (dtos.ReviewResponse, error) {
+	return u.setReviewHidden(ctx, id, true, input.Reason)
+}
+
+// UnhideReview godoc
+// @Summary      Unhide review
+// @Description  Show a hidden review on the product page again
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID review"
+// @Success      200 {object} dtos.ReviewStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/reviews/{id}/unhide [put]
+// @Security BearerAuth
+func (u *reviewUsecase) UnhideReview(ctx context.Context, id uint) (dtos.ReviewResponse, error) {
+	return u.setReviewHidden(ctx, id, false, "")
+}
+
+func (u *reviewUsecase) setReviewHidden(ctx context.Context, id uint, hidden bool, reason string) (dtos.ReviewResponse, error) {
+	var reviewResponse dtos.ReviewResponse
+
+	review, err := u.reviewRepo.GetReviewByID(ctx, id)
+	if err != nil {
+		return reviewResponse, apperrors.NotFound("Review not found")
+	}
+
+	review.Hidden = hidden
+	review.HiddenReason = reason
+
+	review, err = u.reviewRepo.UpdateReview(ctx, review)
+	if err != nil {
+		return reviewResponse, err
+	}
+
+	return toReviewResponse(review), nil
+}
+
+func toReviewResponse(review models.Review) dtos.ReviewResponse {
+	return dtos.ReviewResponse{
+		ReviewID:     review.ID,
+		UserID:       review.UserID,
+		ProductID:    review.ProductID,
+		Rating:       review.Rating,
+		Comment:      review.Comment,
+		Hidden:       review.Hidden,
+		HiddenReason: review.HiddenReason,
+		CreatedAt:    review.CreatedAt,
+		UpdatedAt:    review.UpdatedAt,
+	}
+}
@@ -0,0 +1,297 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/middlewares"
+	"synapsis-backend/models"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+// SetupTwoFactor godoc
+// @Summary      Set up 2FA
+// @Description  Generate a TOTP secret and the provisioning URI to show as a QR code. 2FA stays off until the first code is confirmed at /user/2fa/enable
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.TwoFactorSetupStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/2fa/setup [post]
+// @Security BearerAuth
+func (u *userUsecase) SetupTwoFactor(ctx context.Context, userId uint) (dtos.TwoFactorSetupResponse, error) {
+	var setupResponse dtos.TwoFactorSetupResponse
+
+	user, err := u.userRepo.UserGetById(ctx, userId)
+	if err != nil {
+		return setupResponse, apperrors.NotFound("User not found")
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return setupResponse, apperrors.Conflict("Two-factor authentication is already enabled")
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return setupResponse, err
+	}
+
+	user.TwoFactorSecret = secret
+	if _, err := u.userRepo.UserUpdate(ctx, user); err != nil {
+		return setupResponse, err
+	}
+
+	setupResponse.Secret = secret
+	setupResponse.ProvisioningURI = helpers.TOTPProvisioningURI(u.app.Name, user.Email, secret)
+	return setupResponse, nil
+}
+
+// EnableTwoFactor godoc
+// @Summary      Enable 2FA
+// @Description  Confirm the first code from the authenticator app to turn 2FA on. The recovery codes are only shown once
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.TwoFactorCodeInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.RecoveryCodesStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/2fa/enable [post]
+// @Security BearerAuth
+func (u *userUsecase) EnableTwoFactor(ctx context.Context, userId uint, input dtos.TwoFactorCodeInput) (dtos.RecoveryCodesResponse, error) {
+	var codesResponse dtos.RecoveryCodesResponse
+
+	user, err := u.userRepo.UserGetById(ctx, userId)
+	if err != nil {
+		return codesResponse, apperrors.NotFound("User not found")
+	}
+	if user.TwoFactorEnabledAt != nil {
+		return codesResponse, apperrors.Conflict("Two-factor authentication is already enabled")
+	}
+	if user.TwoFactorSecret == "" {
+		return codesResponse, errors.New("Two-factor authentication is not set up")
+	}
+
+	step, valid := helpers.ValidateTOTP(user.TwoFactorSecret, input.Code, time.Now())
+	if !valid {
+		return codesResponse, errors.New("Invalid code")
+	}
+
+	codes, recoveryCodes, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return codesResponse, err
+	}
+
+	now := time.Now()
+	user.TwoFactorEnabledAt = &now
+	user.TwoFactorLastStep = step
+	if err := u.twoFactorRepo.EnableTwoFactor(ctx, user, recoveryCodes); err != nil {
+		return codesResponse, err
+	}
+
+	codesResponse.RecoveryCodes = codes
+	return codesResponse, nil
+}
+
+// DisableTwoFactor godoc
+// @Summary      Disable 2FA
+// @Description  Turn 2FA off. Needs the password and a TOTP or recovery code
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.TwoFactorDisableInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/2fa/disable [post]
+// @Security BearerAuth
+func (u *userUsecase) DisableTwoFactor(ctx context.Context, userId uint, input dtos.TwoFactorDisableInput) error {
+	user, err := u.userRepo.UserGetById(ctx, userId)
+	if err != nil {
+		return apperrors.NotFound("User not found")
+	}
+	if user.TwoFactorEnabledAt == nil {
+		return errors.New("Two-factor authentication is not enabled")
+	}
+	if !helpers.ComparePassword(input.Password, user.Password) {
+		return errors.New("Password is incorrect")
+	}
+
+	valid, err := u.verifyTwoFactorCode(ctx, user, input.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("Invalid code")
+	}
+
+	return u.twoFactorRepo.DisableTwoFactor(ctx, user)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate recovery codes
+// @Description  Replace the recovery codes with new ones. Needs a TOTP code
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.TwoFactorCodeInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.RecoveryCodesStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/2fa/recovery-codes [post]
+// @Security BearerAuth
+func (u *userUsecase) RegenerateRecoveryCodes(ctx context.Context, userId uint, input dtos.TwoFactorCodeInput) (dtos.RecoveryCodesResponse, error) {
+	var codesResponse dtos.RecoveryCodesResponse
+
+	user, err := u.userRepo.UserGetById(ctx, userId)
+	if err != nil {
+		return codesResponse, apperrors.NotFound("User not found")
+	}
+	if user.TwoFactorEnabledAt == nil {
+		return codesResponse, errors.New("Two-factor authentication is not enabled")
+	}
+
+	step, valid := helpers.ValidateTOTP(user.TwoFactorSecret, input.Code, time.Now())
+	if !valid {
+		return codesResponse, errors.New("Invalid code")
+	}
+	if fresh, err := u.twoFactorRepo.UseTwoFactorStep(ctx, user, step); err != nil || !fresh {
+		return codesResponse, errors.New("Invalid code")
+	}
+
+	codes, recoveryCodes, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return codesResponse, err
+	}
+	if err := u.twoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, recoveryCodes); err != nil {
+		return codesResponse, err
+	}
+
+	codesResponse.RecoveryCodes = codes
+	return codesResponse, nil
+}
+
+// VerifyTwoFactorLogin godoc
+// @Summary      Login with 2FA
+// @Description  Exchange the challenge token from /login and a TOTP or recovery code for an access token
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.TwoFactorLoginInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.UserStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /login/2fa [post]
+func (u *userUsecase) VerifyTwoFactorLogin(ctx context.Context, input dtos.TwoFactorLoginInput, ip string) (dtos.UserInformationResponse, error) {
+	var userResponse dtos.UserInformationResponse
+
+	userId, tokenVersion, err := middlewares.GetUserFromChallengeToken(input.ChallengeToken)
+	if err != nil {
+		return userResponse, errors.New("Invalid or expired challenge token")
+	}
+
+	user, err := u.userRepo.UserGetById(ctx, userId)
+	if err != nil || user.TokenVersion != tokenVersion || user.TwoFactorEnabledAt == nil {
+		return userResponse, errors.New("Invalid or expired challenge token")
+	}
+
+	// Wrong codes count as failed logins, so the code cannot be brute
+	// forced within the lifetime of the challenge token.
+	if err := u.checkLoginThrottle(ctx, user.Email, ip); err != nil {
+		return userResponse, err
+	}
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return userResponse, apperrors.Forbidden("Account is locked until %s", user.LockedUntil.Format(time.RFC3339))
+	}
+
+	valid, err := u.verifyTwoFactorCode(ctx, user, input.Code)
+	if err != nil {
+		return userResponse, err
+	}
+	if !valid {
+		u.recordLoginAttempt(ctx, user.Email, ip, false)
+		if err := u.registerFailedLogin(ctx, user, ip); err != nil {
+			return userResponse, err
+		}
+		return userResponse, errors.New("Invalid code")
+	}
+
+	return u.completeLogin(ctx, user, ip)
+}
+
+// twoFactorChallenge is the login response for users with 2FA on. It holds
+// no access token, only the challenge token for /login/2fa.
+func twoFactorChallenge(user models.User) (dtos.UserInformationResponse, error) {
+	var userResponse dtos.UserInformationResponse
+
+	challengeToken, err := middlewares.CreateChallengeToken(user.ID, user.TokenVersion)
+	if err != nil {
+		return userResponse, err
+	}
+
+	userResponse.ID = user.ID
+	userResponse.Email = user.Email
+	userResponse.TwoFactorEnabled = true
+	userResponse.TwoFactorRequired = true
+	userResponse.ChallengeToken = &challengeToken
+	return userResponse, nil
+}
+
+// verifyTwoFactorCode accepts a TOTP code that was not used before or an
+// unused recovery code, and uses it up.
+func (u *userUsecase) verifyTwoFactorCode(ctx context.Context, user models.User, code string) (bool, error) {
+	if step, valid := helpers.ValidateTOTP(user.TwoFactorSecret, code, time.Now()); valid {
+		return u.twoFactorRepo.UseTwoFactorStep(ctx, user, step)
+	}
+	return u.twoFactorRepo.UseRecoveryCode(ctx, user.ID, helpers.HashToken(normalizeRecoveryCode(code)))
+}
+
+// generateRecoveryCodes returns the codes to show to the user and the
+// hashed rows to store.
+func generateRecoveryCodes(userID uint) ([]string, []models.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	recoveryCodes := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		token, err := helpers.GenerateRandomToken(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, token[:5]+"-"+token[5:])
+		recoveryCodes = append(recoveryCodes, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: helpers.HashToken(token),
+		})
+	}
+	return codes, recoveryCodes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -1,63 +1,181 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/configs"
 	"synapsis-backend/dtos"
 	"synapsis-backend/helpers"
+	"synapsis-backend/mailers"
 	"synapsis-backend/middlewares"
 	"synapsis-backend/models"
+	"synapsis-backend/oidc"
 	"synapsis-backend/repositories"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+
+	// An account is locked for lockoutDuration after maxFailedLogins failed
+	// logins in a row.
+	maxFailedLogins = 5
+	lockoutDuration = 15 * time.Minute
+
+	// Failed logins within loginThrottleWindow slow down further attempts
+	// for the same email or IP, doubling the wait up to maxLoginDelay. An IP
+	// gets more free attempts since many users may share it.
+	loginThrottleWindow = 15 * time.Minute
+	maxLoginDelay       = time.Minute
+	freeLoginsPerEmail  = 2
+	freeLoginsPerIP     = 10
 )
 
 type UserUsecase interface {
-	UserLogin(input dtos.UserLoginInput) (dtos.UserInformationResponse, error)
-	UserRegister(input dtos.UserRegisterInput) (dtos.UserInformationResponse, error)
-	UserUpdateInformation(userId uint, input dtos.UserUpdateInformationInput) (dtos.UserInformationResponse, error)
-	UserUpdatePassword(userId uint, input dtos.UserUpdatePasswordInput) (dtos.UserInformationResponse, error)
-	UserUpdateProfile(userId uint, input dtos.UserUpdateProfileInput) (dtos.UserInformationResponse, error)
-	UserCredential(userId uint) (dtos.UserInformationResponse, error)
+	UserLogin(ctx context.Context, input dtos.UserLoginInput, ip string) (dtos.UserInformationResponse, error)
+	UserRegister(ctx context.Context, input dtos.UserRegisterInput) (dtos.UserInformationResponse, error)
+	UserUpdateInformation(ctx context.Context, userId uint, input dtos.UserUpdateInformationInput) (dtos.UserInformationResponse, error)
+	UserUpdatePassword(ctx context.Context, userId uint, input dtos.UserUpdatePasswordInput) (dtos.UserInformationResponse, error)
+	UserUpdateProfile(ctx context.Context, userId uint, input dtos.UserUpdateProfileInput) (dtos.UserInformationResponse, error)
+	UserCredential(ctx context.Context, userId uint) (dtos.UserInformationResponse, error)
+	VerifyEmail(ctx context.Context, input dtos.VerifyEmailInput) (dtos.UserInformationResponse, error)
+	ResendVerificationEmail(ctx context.Context, userId uint) error
+	ForgotPassword(ctx context.Context, input dtos.ForgotPasswordInput) error
+	ResetPassword(ctx context.Context, input dtos.ResetPasswordInput) error
+	GetLockoutEvents(ctx context.Context, page, limit int, userID uint) ([]dtos.LockoutEventResponse, int, error)
+	UnlockUser(ctx context.Context, adminID, userID uint) error
+	SetupTwoFactor(ctx context.Context, userId uint) (dtos.TwoFactorSetupResponse, error)
+	EnableTwoFactor(ctx context.Context, userId uint, input dtos.TwoFactorCodeInput) (dtos.RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, userId uint, input dtos.TwoFactorDisableInput) error
+	RegenerateRecoveryCodes(ctx context.Context, userId uint, input dtos.TwoFactorCodeInput) (dtos.RecoveryCodesResponse, error)
+	VerifyTwoFactorLogin(ctx context.Context, input dtos.TwoFactorLoginInput, ip string) (dtos.UserInformationResponse, error)
+	GetOIDCAuthURL(ctx context.Context, provider string) (dtos.OIDCAuthResponse, error)
+	OIDCCallback(ctx context.Context, provider string, input dtos.OIDCCallbackInput, cookieState, ip string) (dtos.UserInformationResponse, error)
+	ExportUserData(ctx context.Context, userId uint) (dtos.UserExportResponse, error)
+	DeleteUser(ctx context.Context, userId uint, input dtos.UserDeleteInput) error
+	GetAllUsers(ctx context.Context, page, limit int, input dtos.UserFilterInput) ([]dtos.AdminUserResponse, int, error)
+	GetUserByID(ctx context.Context, id uint) (dtos.AdminUserResponse, error)
+	SuspendUser(ctx context.Context, adminID, userID uint, input dtos.UserSuspendInput) (dtos.AdminUserResponse, error)
+	ReactivateUser(ctx context.Context, userID uint) (dtos.AdminUserResponse, error)
+	ForcePasswordReset(ctx context.Context, userID uint) error
 }
 
 type userUsecase struct {
-	userRepo repositories.UserRepository
+	userRepo              repositories.UserRepository
+	emailVerificationRepo repositories.EmailVerificationRepository
+	passwordResetRepo     repositories.PasswordResetRepository
+	loginAttemptRepo      repositories.LoginAttemptRepository
+	twoFactorRepo         repositories.TwoFactorRepository
+	userIdentityRepo      repositories.UserIdentityRepository
+	mailer                mailers.Mailer
+	oidcProviders         map[string]*oidc.Provider
+	app                   configs.AppConfig
+	log                   *slog.Logger
 }
 
-func NewUserUsecase(userRepo repositories.UserRepository) UserUsecase {
-	return &userUsecase{userRepo}
+func NewUserUsecase(
+	userRepo repositories.UserRepository,
+	emailVerificationRepo repositories.EmailVerificationRepository,
+	passwordResetRepo repositories.PasswordResetRepository,
+	loginAttemptRepo repositories.LoginAttemptRepository,
+	twoFactorRepo repositories.TwoFactorRepository,
+	userIdentityRepo repositories.UserIdentityRepository,
+	mailer mailers.Mailer,
+	oidcProviders map[string]*oidc.Provider,
+	app configs.AppConfig,
+	log *slog.Logger,
+) UserUsecase {
+	return &userUsecase{
+		userRepo,
+		emailVerificationRepo,
+		passwordResetRepo,
+		loginAttemptRepo,
+		twoFactorRepo,
+		userIdentityRepo,
+		mailer,
+		oidcProviders,
+		app,
+		log,
+	}
 }
 
 // UserLogin godoc
 // @Summary      Login
-// @Description  Login an account
+// @Description  Login an account. Repeated failures slow down further attempts and lock the account for a while
 // @Tags         User
 // @Accept       json
 // @Produce      json
 // @Param        request body dtos.UserLoginInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.UserStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /login [post]
-func (u *userUsecase) UserLogin(input dtos.UserLoginInput) (dtos.UserInformationResponse, error) {
-	var (
-		userResponse dtos.UserInformationResponse
-		accessToken  string
-	)
+func (u *userUsecase) UserLogin(ctx context.Context, input dtos.UserLoginInput, ip string) (dtos.UserInformationResponse, error) {
+	var userResponse dtos.UserInformationResponse
 
-	user, err := u.userRepo.UserGetByEmail(input.Email)
+	// Throttling is checked before bcrypt so that guessing stays cheap for us
+	// and slow for the attacker.
+	if err := u.checkLoginThrottle(ctx, input.Email, ip); err != nil {
+		return userResponse, err
+	}
+
+	user, err := u.userRepo.UserGetByEmail(ctx, input.Email)
 	if err != nil {
+		u.recordLoginAttempt(ctx, input.Email, ip, false)
 		return userResponse, errors.New("Email or password is wrong")
 	}
 
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return userResponse, apperrors.Forbidden("Account is locked until %s", user.LockedUntil.Format(time.RFC3339))
+	}
+
 	valid := helpers.ComparePassword(input.Password, user.Password)
 	if !valid {
+		u.recordLoginAttempt(ctx, input.Email, ip, false)
+		if err := u.registerFailedLogin(ctx, user, ip); err != nil {
+			u.log.ErrorContext(ctx, "failed to register failed login", slog.Any("error", err))
+		}
 		return userResponse, errors.New("Email or password is wrong")
 	}
 
-	accessToken, err = middlewares.CreateToken(user.ID)
+	// With 2FA on the password only earns a challenge token, the login is
+	// completed by VerifyTwoFactorLogin.
+	if user.TwoFactorEnabledAt != nil {
+		return twoFactorChallenge(user)
+	}
+
+	return u.completeLogin(ctx, user, ip)
+}
+
+// completeLogin records the successful login, clears the failure count and
+// issues the access token.
+func (u *userUsecase) completeLogin(ctx context.Context, user models.User, ip string) (dtos.UserInformationResponse, error) {
+	var userResponse dtos.UserInformationResponse
+
+	if user.SuspendedAt != nil {
+		return userResponse, apperrors.Forbidden("Account is suspended")
+	}
+
+	u.recordLoginAttempt(ctx, user.Email, ip, true)
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		user.FailedLogins = 0
+		user.LockedUntil = nil
+		if err := u.userRepo.UpdateLoginFailures(ctx, user); err != nil {
+			return userResponse, err
+		}
+	}
+
+	accessToken, err := middlewares.CreateToken(user.ID, user.Role, user.TokenVersion)
 	if err != nil {
 		return userResponse, err
 	}
@@ -70,6 +188,9 @@ func (u *userUsecase) UserLogin(input dtos.UserLoginInput) (dtos.UserInformation
 	userResponse.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
 	// userResponse.ProfilePicture = user.ProfilePicture
 	userResponse.Citizen = user.Citizen
+	userResponse.Role = user.Role
+	userResponse.EmailVerified = user.EmailVerifiedAt != nil
+	userResponse.TwoFactorEnabled = user.TwoFactorEnabledAt != nil
 	userResponse.Token = &accessToken
 	userResponse.CreatedAt = user.CreatedAt
 	userResponse.UpdatedAt = user.UpdatedAt
@@ -86,24 +207,26 @@ func (u *userUsecase) UserLogin(input dtos.UserLoginInput) (dtos.UserInformation
 // @Param        request body dtos.UserRegisterInput true "Payload Body [RAW]"
 // @Success      201 {object} dtos.UserCreatedResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /register [post]
-func (u *userUsecase) UserRegister(input dtos.UserRegisterInput) (dtos.UserInformationResponse, error) {
+func (u *userUsecase) UserRegister(ctx context.Context, input dtos.UserRegisterInput) (dtos.UserInformationResponse, error) {
 	var (
 		user         models.User
 		userResponse dtos.UserInformationResponse
 	)
 
-	user, err := u.userRepo.UserGetByEmail(input.Email)
+	user, err := u.userRepo.UserGetByEmail(ctx, input.Email)
 	if user.ID > 0 {
-		return userResponse, errors.New("Email already used")
+		return userResponse, apperrors.Conflict("Email already used")
 	}
 
 	if input.Password != input.ConfirmPassword {
-		return userResponse, errors.New("Password does not match")
+		return userResponse, apperrors.Validation("Password does not match")
 	}
 
 	password, err := helpers.HashPassword(input.Password)
@@ -111,22 +234,25 @@ func (u *userUsecase) UserRegister(input dtos.UserRegisterInput) (dtos.UserInfor
 		return userResponse, err
 	}
 
-	if input.Email == "" || input.FullName == "" || input.Password == "" || input.ConfirmPassword == "" {
-		return userResponse, errors.New("Failed to create user")
-	}
-
 	user.FullName = input.FullName
 	user.Email = input.Email
 	user.Password = password
 	user.PhoneNumber = input.PhoneNumber
 	// user.ProfilePicture = "default.jpg"
 	user.Citizen = "Indonesia"
+	user.Role = models.RoleUser
 
-	user, err = u.userRepo.UserCreate(user)
+	user, err = u.userRepo.UserCreate(ctx, user)
 	if err != nil {
 		return userResponse, err
 	}
 
+	// The account exists even when the mail fails, the user can ask for a
+	// new verification email later.
+	if err := u.sendVerificationEmail(ctx, user); err != nil {
+		u.log.ErrorContext(ctx, "failed to send verification email", slog.Any("error", err))
+	}
+
 	userResponse.ID = user.ID
 	userResponse.FullName = user.FullName
 	userResponse.Email = user.Email
@@ -135,6 +261,9 @@ func (u *userUsecase) UserRegister(input dtos.UserRegisterInput) (dtos.UserInfor
 	userResponse.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
 	// userResponse.ProfilePicture = user.ProfilePicture
 	userResponse.Citizen = user.Citizen
+	userResponse.Role = user.Role
+	userResponse.EmailVerified = user.EmailVerifiedAt != nil
+	userResponse.TwoFactorEnabled = user.TwoFactorEnabledAt != nil
 	userResponse.CreatedAt = user.CreatedAt
 	userResponse.UpdatedAt = user.UpdatedAt
 
@@ -150,21 +279,22 @@ func (u *userUsecase) UserRegister(input dtos.UserRegisterInput) (dtos.UserInfor
 // @Param        request body dtos.UserUpdateInformationInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.UserStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /user/update-information [patch]
 // @Security BearerAuth
-func (u *userUsecase) UserUpdateInformation(userId uint, input dtos.UserUpdateInformationInput) (dtos.UserInformationResponse, error) {
+func (u *userUsecase) UserUpdateInformation(ctx context.Context, userId uint, input dtos.UserUpdateInformationInput) (dtos.UserInformationResponse, error) {
 	var (
 		user         models.User
 		userResponse dtos.UserInformationResponse
 	)
 
-	user, err := u.userRepo.UserGetById(userId)
+	user, err := u.userRepo.UserGetById(ctx, userId)
 	if err != nil {
-		return userResponse, errors.New("User not found")
+		return userResponse, apperrors.NotFound("User not found")
 	}
 
 	birthDateParse := helpers.FormatStringToDate(input.BirthDate)
@@ -173,7 +303,7 @@ func (u *userUsecase) UserUpdateInformation(userId uint, input dtos.UserUpdateIn
 	user.Gender = input.Gender
 	user.BirthDate = &birthDateParse
 
-	user, err = u.userRepo.UserUpdate(user)
+	user, err = u.userRepo.UserUpdate(ctx, user)
 	if err != nil {
 		return userResponse, err
 	}
@@ -186,6 +316,9 @@ func (u *userUsecase) UserUpdateInformation(userId uint, input dtos.UserUpdateIn
 	userResponse.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
 	// userResponse.ProfilePicture = user.ProfilePicture
 	userResponse.Citizen = user.Citizen
+	userResponse.Role = user.Role
+	userResponse.EmailVerified = user.EmailVerifiedAt != nil
+	userResponse.TwoFactorEnabled = user.TwoFactorEnabledAt != nil
 	userResponse.CreatedAt = user.CreatedAt
 	userResponse.UpdatedAt = user.UpdatedAt
 
@@ -201,21 +334,22 @@ func (u *userUsecase) UserUpdateInformation(userId uint, input dtos.UserUpdateIn
 // @Param        request body dtos.UserUpdatePasswordInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.UserStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /user/update-password [put]
 // @Security BearerAuth
-func (u *userUsecase) UserUpdatePassword(userId uint, input dtos.UserUpdatePasswordInput) (dtos.UserInformationResponse, error) {
+func (u *userUsecase) UserUpdatePassword(ctx context.Context, userId uint, input dtos.UserUpdatePasswordInput) (dtos.UserInformationResponse, error) {
 	var (
 		user         models.User
 		userResponse dtos.UserInformationResponse
 	)
 
-	user, err := u.userRepo.UserGetById(userId)
+	user, err := u.userRepo.UserGetById(ctx, userId)
 	if err != nil {
-		return userResponse, errors.New("User not found")
+		return userResponse, apperrors.NotFound("User not found")
 	}
 
 	if input.OldPassword == "" || input.NewPassword == "" || input.ConfirmPassword == "" {
@@ -228,11 +362,11 @@ func (u *userUsecase) UserUpdatePassword(userId uint, input dtos.UserUpdatePassw
 	}
 
 	if input.OldPassword == input.NewPassword {
-		return userResponse, errors.New("New password must be different")
+		return userResponse, apperrors.Validation("New password must be different")
 	}
 
 	if input.NewPassword != input.ConfirmPassword {
-		return userResponse, errors.New("Confirm password does not match")
+		return userResponse, apperrors.Validation("Confirm password does not match")
 	}
 
 	newPassword, err := helpers.HashPassword(input.ConfirmPassword)
@@ -242,7 +376,7 @@ func (u *userUsecase) UserUpdatePassword(userId uint, input dtos.UserUpdatePassw
 
 	user.Password = newPassword
 
-	user, err = u.userRepo.UserUpdate(user)
+	user, err = u.userRepo.UserUpdate(ctx, user)
 	if err != nil {
 		return userResponse, err
 	}
@@ -255,6 +389,9 @@ func (u *userUsecase) UserUpdatePassword(userId uint, input dtos.UserUpdatePassw
 	userResponse.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
 	// userResponse.ProfilePicture = user.ProfilePicture
 	userResponse.Citizen = user.Citizen
+	userResponse.Role = user.Role
+	userResponse.EmailVerified = user.EmailVerifiedAt != nil
+	userResponse.TwoFactorEnabled = user.TwoFactorEnabledAt != nil
 	userResponse.CreatedAt = user.CreatedAt
 	userResponse.UpdatedAt = user.UpdatedAt
 
@@ -270,21 +407,22 @@ func (u *userUsecase) UserUpdatePassword(userId uint, input dtos.UserUpdatePassw
 // @Param        request body dtos.UserUpdateProfileInput true "Payload Body [RAW]"
 // @Success      200 {object} dtos.UserStatusOKResponse
 // @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
 // @Failure      401 {object} dtos.UnauthorizedResponse
 // @Failure      403 {object} dtos.ForbiddenResponse
 // @Failure      404 {object} dtos.NotFoundResponse
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /user/update-profile [put]
 // @Security BearerAuth
-func (u *userUsecase) UserUpdateProfile(userId uint, input dtos.UserUpdateProfileInput) (dtos.UserInformationResponse, error) {
+func (u *userUsecase) UserUpdateProfile(ctx context.Context, userId uint, input dtos.UserUpdateProfileInput) (dtos.UserInformationResponse, error) {
 	var (
 		user         models.User
 		userResponse dtos.UserInformationResponse
 	)
 
-	user, err := u.userRepo.UserGetById(userId)
+	user, err := u.userRepo.UserGetById(ctx, userId)
 	if err != nil {
-		return userResponse, errors.New("User not found")
+		return userResponse, apperrors.NotFound("User not found")
 	}
 
 	dateNow := "2006-01-02"
@@ -295,7 +433,7 @@ func (u *userUsecase) UserUpdateProfile(userId uint, input dtos.UserUpdateProfil
 	user.BirthDate = &birthDateParse
 	user.Citizen = input.Citizen
 
-	user, err = u.userRepo.UserUpdate(user)
+	user, err = u.userRepo.UserUpdate(ctx, user)
 	if err != nil {
 		return userResponse, err
 	}
@@ -308,6 +446,9 @@ func (u *userUsecase) UserUpdateProfile(userId uint, input dtos.UserUpdateProfil
 	userResponse.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
 	// userResponse.ProfilePicture = user.ProfilePicture
 	userResponse.Citizen = user.Citizen
+	userResponse.Role = user.Role
+	userResponse.EmailVerified = user.EmailVerifiedAt != nil
+	userResponse.TwoFactorEnabled = user.TwoFactorEnabledAt != nil
 	userResponse.CreatedAt = user.CreatedAt
 	userResponse.UpdatedAt = user.UpdatedAt
 
@@ -328,15 +469,15 @@ func (u *userUsecase) UserUpdateProfile(userId uint, input dtos.UserUpdateProfil
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /user [get]
 // @Security BearerAuth
-func (u *userUsecase) UserCredential(userId uint) (dtos.UserInformationResponse, error) {
+func (u *userUsecase) UserCredential(ctx context.Context, userId uint) (dtos.UserInformationResponse, error) {
 	var (
 		user         models.User
 		userResponse dtos.UserInformationResponse
 	)
 
-	user, err := u.userRepo.UserGetById(userId)
+	user, err := u.userRepo.UserGetById(ctx, userId)
 	if err != nil {
-		return userResponse, errors.New("User not found")
+		return userResponse, apperrors.NotFound("User not found")
 	}
 
 	userResponse.ID = user.ID
@@ -347,8 +488,402 @@ func (u *userUsecase) UserCredential(userId uint) (dtos.UserInformationResponse,
 	userResponse.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
 	// userResponse.ProfilePicture = user.ProfilePicture
 	userResponse.Citizen = user.Citizen
+	userResponse.Role = user.Role
+	userResponse.EmailVerified = user.EmailVerifiedAt != nil
+	userResponse.TwoFactorEnabled = user.TwoFactorEnabledAt != nil
 	userResponse.CreatedAt = user.CreatedAt
 	userResponse.UpdatedAt = user.UpdatedAt
 
 	return userResponse, err
 }
+
+// VerifyEmail godoc
+// @Summary      Verify email
+// @Description  Confirm the email of an account with the token from the verification email
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.VerifyEmailInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.UserStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /verify-email [post]
+func (u *userUsecase) VerifyEmail(ctx context.Context, input dtos.VerifyEmailInput) (dtos.UserInformationResponse, error) {
+	var userResponse dtos.UserInformationResponse
+
+	if input.Token == "" {
+		return userResponse, apperrors.Validation("Token is required")
+	}
+
+	verification, err := u.emailVerificationRepo.GetEmailVerificationByTokenHash(ctx, helpers.HashToken(input.Token))
+	if err != nil {
+		return userResponse, errors.New("Invalid verification token")
+	}
+	if verification.UsedAt != nil {
+		return userResponse, apperrors.Conflict("Verification token already used")
+	}
+	if time.Now().After(verification.ExpiresAt) {
+		return userResponse, errors.New("Verification token expired")
+	}
+
+	user, err := u.userRepo.UserGetById(ctx, verification.UserID)
+	if err != nil {
+		return userResponse, apperrors.NotFound("User not found")
+	}
+
+	now := time.Now()
+	verification.UsedAt = &now
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+	}
+	if err := u.emailVerificationRepo.VerifyEmail(ctx, verification, user); err != nil {
+		return userResponse, err
+	}
+
+	userResponse.ID = user.ID
+	userResponse.FullName = user.FullName
+	userResponse.Email = user.Email
+	userResponse.PhoneNumber = user.PhoneNumber
+	userResponse.Gender = user.Gender
+	userResponse.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
+	userResponse.Citizen = user.Citizen
+	userResponse.Role = user.Role
+	userResponse.EmailVerified = true
+	userResponse.CreatedAt = user.CreatedAt
+	userResponse.UpdatedAt = user.UpdatedAt
+
+	return userResponse, nil
+}
+
+// ResendVerificationEmail godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification email to the logged in user
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/resend-verification [post]
+// @Security BearerAuth
+func (u *userUsecase) ResendVerificationEmail(ctx context.Context, userId uint) error {
+	user, err := u.userRepo.UserGetById(ctx, userId)
+	if err != nil {
+		return apperrors.NotFound("User not found")
+	}
+	if user.EmailVerifiedAt != nil {
+		return apperrors.Conflict("Email already verified")
+	}
+
+	return u.sendVerificationEmail(ctx, user)
+}
+
+func (u *userUsecase) sendVerificationEmail(ctx context.Context, user models.User) error {
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.emailVerificationRepo.CreateEmailVerification(ctx, models.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(ctx, mailers.Mail{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nConfirm your email by opening %s/api/v1/verify-email?token=%s\n\nThe link expires in 24 hours.",
+			user.FullName, u.app.URL, token,
+		),
+	})
+}
+
+// ForgotPassword godoc
+// @Summary      Forgot password
+// @Description  Send a password reset link to the email. The response is the same whether the email is registered or not
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.ForgotPasswordInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /forgot-password [post]
+func (u *userUsecase) ForgotPassword(ctx context.Context, input dtos.ForgotPasswordInput) error {
+	if input.Email == "" {
+		return apperrors.Validation("Email is required")
+	}
+
+	// Unknown emails and failures are only logged, so the response does not
+	// tell whether the email is registered.
+	user, err := u.userRepo.UserGetByEmail(ctx, input.Email)
+	if err != nil {
+		return nil
+	}
+
+	if err := u.sendPasswordReset(ctx, user); err != nil {
+		u.log.ErrorContext(ctx, "failed to send password reset", slog.Any("error", err))
+	}
+
+	return nil
+}
+
+// sendPasswordReset creates a reset token for the user and mails it.
+func (u *userUsecase) sendPasswordReset(ctx context.Context, user models.User) error {
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.passwordResetRepo.CreatePasswordReset(ctx, models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(ctx, mailers.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse this token to reset your password: %s\n\nOr open %s/reset-password?token=%s\n\nThe token expires in 1 hour. Ignore this email if you did not ask for a reset.",
+			user.FullName, token, u.app.URL, token,
+		),
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password with the token from the reset email. Every session of the user is logged out
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.ResetPasswordInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /reset-password [post]
+func (u *userUsecase) ResetPassword(ctx context.Context, input dtos.ResetPasswordInput) error {
+	if input.Token == "" || input.NewPassword == "" || input.ConfirmPassword == "" {
+		return errors.New("Failed to reset password")
+	}
+	if input.NewPassword != input.ConfirmPassword {
+		return apperrors.Validation("Confirm password does not match")
+	}
+
+	reset, err := u.passwordResetRepo.GetPasswordResetByTokenHash(ctx, helpers.HashToken(input.Token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return errors.New("Invalid or expired reset token")
+	}
+
+	user, err := u.userRepo.UserGetById(ctx, reset.UserID)
+	if err != nil {
+		return errors.New("Invalid or expired reset token")
+	}
+
+	password, err := helpers.HashPassword(input.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reset.UsedAt = &now
+	user.Password = password
+	user.TokenVersion++
+
+	err = u.passwordResetRepo.ResetPassword(ctx, reset, user)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("Invalid or expired reset token")
+	}
+	return err
+}
+
+// GetLockoutEvents godoc
+// @Summary      Get lockout events
+// @Description  Get the accounts locked after too many failed logins, newest first
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param user_id query int false "Search by user ID"
+// @Success      200 {object} dtos.GetAllLockoutEventStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/lockouts [get]
+// @Security BearerAuth
+func (u *userUsecase) GetLockoutEvents(ctx context.Context, page, limit int, userID uint) ([]dtos.LockoutEventResponse, int, error) {
+	events, count, err := u.loginAttemptRepo.GetAllLockoutEvents(ctx, page, limit, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	eventResponses := []dtos.LockoutEventResponse{}
+	for _, event := range events {
+		eventResponses = append(eventResponses, dtos.LockoutEventResponse{
+			LockoutEventID: event.ID,
+			UserID:         event.UserID,
+			Email:          event.Email,
+			IP:             event.IP,
+			FailedAttempts: event.FailedAttempts,
+			LockedUntil:    event.LockedUntil,
+			UnlockedAt:     event.UnlockedAt,
+			UnlockedBy:     event.UnlockedBy,
+			CreatedAt:      event.CreatedAt,
+		})
+	}
+
+	return eventResponses, count, nil
+}
+
+// UnlockUser godoc
+// @Summary      Unlock user
+// @Description  Lift the login lock of an account before it expires
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID user"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/users/{id}/unlock [post]
+// @Security BearerAuth
+func (u *userUsecase) UnlockUser(ctx context.Context, adminID, userID uint) error {
+	user, err := u.userRepo.UserGetById(ctx, userID)
+	if err != nil {
+		return apperrors.NotFound("User not found")
+	}
+	if user.LockedUntil == nil {
+		return errors.New("User is not locked")
+	}
+
+	return u.loginAttemptRepo.UnlockUser(ctx, user, adminID)
+}
+
+// checkLoginThrottle rejects the login when the email or IP has failed too
+// often recently and its wait is not over yet.
+func (u *userUsecase) checkLoginThrottle(ctx context.Context, email, ip string) error {
+	since := time.Now().Add(-loginThrottleWindow)
+
+	emailFailures, emailLastAt, err := u.loginAttemptRepo.GetFailedLoginsByEmail(ctx, email, since)
+	if err != nil {
+		return err
+	}
+	ipFailures, ipLastAt, err := u.loginAttemptRepo.GetFailedLoginsByIP(ctx, ip, since)
+	if err != nil {
+		return err
+	}
+
+	retryAt := time.Time{}
+	if emailLastAt != nil {
+		retryAt = emailLastAt.Add(loginDelay(emailFailures - freeLoginsPerEmail))
+	}
+	if ipLastAt != nil {
+		if ipRetryAt := ipLastAt.Add(loginDelay(ipFailures - freeLoginsPerIP)); ipRetryAt.After(retryAt) {
+			retryAt = ipRetryAt
+		}
+	}
+
+	if wait := time.Until(retryAt); wait > 0 {
+		return fmt.Errorf("Too many login attempts, try again in %d seconds", int(wait.Seconds())+1)
+	}
+	return nil
+}
+
+// loginDelay doubles from one second for every failure past the free ones.
+func loginDelay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	// Checked before shifting so large counts cannot overflow.
+	if failures > 6 {
+		return maxLoginDelay
+	}
+	return time.Second << (failures - 1)
+}
+
+// registerFailedLogin counts the failure on the account and locks it once
+// maxFailedLogins is reached.
+func (u *userUsecase) registerFailedLogin(ctx context.Context, user models.User, ip string) error {
+	user.FailedLogins++
+	if user.FailedLogins < maxFailedLogins {
+		return u.userRepo.UpdateLoginFailures(ctx, user)
+	}
+
+	lockedUntil := time.Now().Add(lockoutDuration)
+	failedAttempts := user.FailedLogins
+	user.FailedLogins = 0
+	user.LockedUntil = &lockedUntil
+	if err := u.userRepo.UpdateLoginFailures(ctx, user); err != nil {
+		return err
+	}
+
+	u.log.WarnContext(ctx, "account locked",
+		slog.Uint64("account_id", uint64(user.ID)),
+		slog.String("email", user.Email),
+		slog.Int("failed_logins", failedAttempts),
+		slog.String("ip", ip),
+	)
+	_, err := u.loginAttemptRepo.CreateLockoutEvent(ctx, models.LockoutEvent{
+		UserID:         user.ID,
+		Email:          user.Email,
+		IP:             ip,
+		FailedAttempts: failedAttempts,
+		LockedUntil:    lockedUntil,
+	})
+	return err
+}
+
+func (u *userUsecase) recordLoginAttempt(ctx context.Context, email, ip string, success bool) {
+	_, err := u.loginAttemptRepo.CreateLoginAttempt(ctx, models.LoginAttempt{
+		Email:   email,
+		IP:      ip,
+		Success: success,
+	})
+	if err != nil {
+		u.log.ErrorContext(ctx, "failed to record login attempt", slog.Any("error", err))
+	}
+}
+
+// ensureEmailVerified returns an error when the user has not confirmed their
+// email yet.
+func ensureEmailVerified(ctx context.Context, userRepo repositories.UserRepository, userID uint) error {
+	user, err := userRepo.UserGetById(ctx, userID)
+	if err != nil {
+		return apperrors.NotFound("User not found")
+	}
+	if user.EmailVerifiedAt == nil {
+		return apperrors.Forbidden("Email is not verified, please verify your email first")
+	}
+	return nil
+}
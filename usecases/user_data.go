@@ -0,0 +1,163 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/helpers"
+	"synapsis-backend/models"
+	"time"
+)
+
+// ExportUserData godoc
+// @Summary      Export my data
+// @Description  Export the profile, carts, orders, payments and reviews of the account. Use format=zip to download a ZIP with one JSON file per section
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Produce      application/zip
+// @Param        format query string false "json (default) or zip"
+// @Success      200 {object} dtos.UserExportStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/export [get]
+// @Security BearerAuth
+func (u *userUsecase) ExportUserData(ctx context.Context, userId uint) (dtos.UserExportResponse, error) {
+	var export dtos.UserExportResponse
+
+	user, err := u.userRepo.UserGetExportData(ctx, userId)
+	if err != nil {
+		return export, apperrors.NotFound("User not found")
+	}
+
+	export.Profile.ID = user.ID
+	export.Profile.FullName = user.FullName
+	export.Profile.Email = user.Email
+	export.Profile.PhoneNumber = user.PhoneNumber
+	export.Profile.Gender = user.Gender
+	export.Profile.BirthDate = helpers.FormatDateToYMD(user.BirthDate)
+	export.Profile.Citizen = user.Citizen
+	export.Profile.Role = user.Role
+	export.Profile.EmailVerified = user.EmailVerifiedAt != nil
+	export.Profile.TwoFactorEnabled = user.TwoFactorEnabledAt != nil
+	export.Profile.CreatedAt = user.CreatedAt
+	export.Profile.UpdatedAt = user.UpdatedAt
+
+	export.Carts = []dtos.CartResponse{}
+	for _, cart := range user.Carts {
+		export.Carts = append(export.Carts, dtos.CartResponse{
+			CartID:    cart.ID,
+			UserID:    cart.UserID,
+			ProductID: cart.ProductID,
+			Price:     cart.Price,
+			Quantity:  cart.Quantity,
+			CreatedAt: cart.CreatedAt,
+			UpdatedAt: cart.UpdatedAt,
+		})
+	}
+
+	export.Orders = []dtos.OrderResponseCheckout{}
+	for _, order := range user.Orders {
+		details := []dtos.OrderDetailResponse{}
+		for _, detail := range order.OrderDetail {
+			details = append(details, dtos.OrderDetailResponse{
+				OrderDetailID: detail.ID,
+				ProductID:     detail.ProductID,
+				OrderID:       detail.OrderID,
+				WarehouseID:   detail.WarehouseID,
+				Quantity:      detail.Quantity,
+				SubTotal:      detail.SubTotal,
+				Discount:      detail.Discount,
+				CreatedAt:     detail.CreatedAt,
+				UpdatedAt:     detail.UpdatedAt,
+			})
+		}
+		export.Orders = append(export.Orders, dtos.OrderResponseCheckout{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			TotalPrice:      order.TotalPrice,
+			Status:          order.Status,
+			ShippingAddress: order.ShippingAddress,
+			OrderDetail:     details,
+			CreatedAt:       order.CreatedAt,
+			UpdatedAt:       order.UpdatedAt,
+		})
+	}
+
+	export.Payments = []dtos.PaymentResponse{}
+	for _, payment := range user.Payments {
+		export.Payments = append(export.Payments, dtos.PaymentResponse{
+			PaymentID:   payment.ID,
+			OrderID:     payment.OrderID,
+			UserID:      payment.UserID,
+			PaymentType: payment.PaymentType,
+			Amount:      payment.Amount,
+			CreatedAt:   payment.CreatedAt,
+			UpdatedAt:   payment.UpdatedAt,
+		})
+	}
+
+	export.Reviews = []dtos.ReviewResponse{}
+	for _, review := range user.Reviews {
+		export.Reviews = append(export.Reviews, dtos.ReviewResponse{
+			ReviewID:     review.ID,
+			UserID:       review.UserID,
+			ProductID:    review.ProductID,
+			Rating:       review.Rating,
+			Comment:      review.Comment,
+			Hidden:       review.Hidden,
+			HiddenReason: review.HiddenReason,
+			CreatedAt:    review.CreatedAt,
+			UpdatedAt:    review.UpdatedAt,
+		})
+	}
+
+	export.ExportedAt = time.Now()
+
+	return export, nil
+}
+
+// DeleteUser godoc
+// @Summary      Delete my account
+// @Description  Delete the account. Personal data is removed, orders and payments are kept anonymised for accounting. Needs the password, and a TOTP or recovery code when 2FA is on. Accounts created by social login can set a password with forgot password first
+// @Tags         User
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.UserDeleteInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user [delete]
+// @Security BearerAuth
+func (u *userUsecase) DeleteUser(ctx context.Context, userId uint, input dtos.UserDeleteInput) error {
+	user, err := u.userRepo.UserGetById(ctx, userId)
+	if err != nil {
+		return apperrors.NotFound("User not found")
+	}
+	if user.Role == models.RoleAdmin {
+		return apperrors.Forbidden("Admin accounts cannot be deleted")
+	}
+	if !helpers.ComparePassword(input.Password, user.Password) {
+		return errors.New("Password is incorrect")
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		valid, err := u.verifyTwoFactorCode(ctx, user, input.Code)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return errors.New("Invalid code")
+		}
+	}
+
+	return u.userRepo.UserAnonymize(ctx, user)
+}
@@ -0,0 +1,342 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/models"
+	"synapsis-backend/repositories"
+)
+
+type WarehouseUsecase interface {
+	GetAllWarehouses(ctx context.Context, page, limit int) ([]dtos.WarehouseResponse, int, error)
+	GetWarehouseByID(ctx context.Context, id uint) (dtos.WarehouseResponse, error)
+	CreateWarehouse(ctx context.Context, warehouse *dtos.WarehouseInput) (dtos.WarehouseResponse, error)
+	UpdateWarehouse(ctx context.Context, id uint, warehouseInput dtos.WarehouseInput) (dtos.WarehouseResponse, error)
+	DeleteWarehouse(ctx context.Context, id uint) error
+	GetWarehouseStocks(ctx context.Context, id uint) ([]dtos.WarehouseStockResponse, error)
+	GetAllStockTransfers(ctx context.Context, page, limit int, productID uint) ([]dtos.StockTransferResponse, int, error)
+	TransferStock(ctx context.Context, userID uint, input dtos.StockTransferInput) (dtos.StockTransferResponse, error)
+}
+
+type warehouseUsecase struct {
+	warehouseRepo repositories.WarehouseRepository
+	productRepo   repositories.ProductRepository
+}
+
+func NewWarehouseUsecase(
+	WarehouseRepo repositories.WarehouseRepository,
+	ProductRepo repositories.ProductRepository,
+) WarehouseUsecase {
+	return &warehouseUsecase{WarehouseRepo, ProductRepo}
+}
+
+// GetAllWarehouses godoc
+// @Summary      Get all warehouse
+// @Description  Get all warehouse
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success      200 {object} dtos.GetAllWarehouseStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/warehouses [get]
+// @Security BearerAuth
+func (u *warehouseUsecase) GetAllWarehouses(ctx context.Context, page, limit int) ([]dtos.WarehouseResponse, int, error) {
+	warehouses, count, err := u.warehouseRepo.GetAllWarehouses(ctx, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	warehouseResponses := []dtos.WarehouseResponse{}
+	for _, warehouse := range warehouses {
+		warehouseResponses = append(warehouseResponses, toWarehouseResponse(warehouse))
+	}
+
+	return warehouseResponses, count, nil
+}
+
+// GetWarehouseByID godoc
+// @Summary      Get warehouse by ID
+// @Description  Get warehouse by ID
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID warehouse"
+// @Success      200 {object} dtos.WarehouseStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/warehouses/{id} [get]
+// @Security BearerAuth
+func (u *warehouseUsecase) GetWarehouseByID(ctx context.Context, id uint) (dtos.WarehouseResponse, error) {
+	warehouse, err := u.warehouseRepo.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return dtos.WarehouseResponse{}, apperrors.NotFound("Warehouse not found")
+	}
+	return toWarehouseResponse(warehouse), nil
+}
+
+// CreateWarehouse godoc
+// @Summary      Create a new warehouse
+// @Description  Create a new warehouse. Latitude and longitude are used by the 'nearest' allocation strategy
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.WarehouseInput true "Payload Body [RAW]"
+// @Success      201 {object} dtos.WarehouseCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/warehouses [post]
+// @Security BearerAuth
+func (u *warehouseUsecase) CreateWarehouse(ctx context.Context, warehouse *dtos.WarehouseInput) (dtos.WarehouseResponse, error) {
+	if warehouse.Name == "" {
+		return dtos.WarehouseResponse{}, apperrors.Validation("Name is required")
+	}
+
+	createdWarehouse, err := u.warehouseRepo.CreateWarehouse(ctx, models.Warehouse{
+		Name:      warehouse.Name,
+		Address:   warehouse.Address,
+		Latitude:  warehouse.Latitude,
+		Longitude: warehouse.Longitude,
+	})
+	if err != nil {
+		return dtos.WarehouseResponse{}, err
+	}
+
+	return toWarehouseResponse(createdWarehouse), nil
+}
+
+// UpdateWarehouse godoc
+// @Summary      Update warehouse
+// @Description  Update warehouse
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID warehouse"
+// @Param        request body dtos.WarehouseInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.WarehouseStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/warehouses/{id} [put]
+// @Security BearerAuth
+func (u *warehouseUsecase) UpdateWarehouse(ctx context.Context, id uint, warehouseInput dtos.WarehouseInput) (dtos.WarehouseResponse, error) {
+	warehouse, err := u.warehouseRepo.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return dtos.WarehouseResponse{}, apperrors.NotFound("Warehouse not found")
+	}
+	if warehouseInput.Name == "" {
+		return dtos.WarehouseResponse{}, apperrors.Validation("Name is required")
+	}
+
+	warehouse.Name = warehouseInput.Name
+	warehouse.Address = warehouseInput.Address
+	warehouse.Latitude = warehouseInput.Latitude
+	warehouse.Longitude = warehouseInput.Longitude
+
+	warehouse, err = u.warehouseRepo.UpdateWarehouse(ctx, warehouse)
+	if err != nil {
+		return dtos.WarehouseResponse{}, err
+	}
+
+	return toWarehouseResponse(warehouse), nil
+}
+
+// DeleteWarehouse godoc
+// @Summary      Delete a warehouse
+// @Description  Delete a warehouse. A warehouse still holding stock must be emptied with stock transfers first
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID warehouse"
+// @Success      200 {object} dtos.StatusOKDeletedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/warehouses/{id} [delete]
+// @Security BearerAuth
+func (u *warehouseUsecase) DeleteWarehouse(ctx context.Context, id uint) error {
+	warehouse, err := u.warehouseRepo.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return apperrors.NotFound("Warehouse not found")
+	}
+
+	stocks, err := u.warehouseRepo.GetStocksByWarehouseID(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, stock := range stocks {
+		if stock.Stock > 0 {
+			return apperrors.Conflict("Warehouse still holds stock, transfer it to another warehouse first")
+		}
+	}
+
+	return u.warehouseRepo.DeleteWarehouse(ctx, warehouse)
+}
+
+// GetWarehouseStocks godoc
+// @Summary      Get warehouse stocks
+// @Description  Get the stock of every product in a warehouse
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID warehouse"
+// @Success      200 {object} dtos.WarehouseStockStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/warehouses/{id}/stocks [get]
+// @Security BearerAuth
+func (u *warehouseUsecase) GetWarehouseStocks(ctx context.Context, id uint) ([]dtos.WarehouseStockResponse, error) {
+	if _, err := u.warehouseRepo.GetWarehouseByID(ctx, id); err != nil {
+		return nil, apperrors.NotFound("Warehouse not found")
+	}
+
+	stocks, err := u.warehouseRepo.GetStocksByWarehouseID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stockResponses := []dtos.WarehouseStockResponse{}
+	for _, stock := range stocks {
+		stockResponses = append(stockResponses, dtos.WarehouseStockResponse{
+			WarehouseID: stock.WarehouseID,
+			ProductID:   stock.ProductID,
+			Stock:       stock.Stock,
+			UpdatedAt:   stock.UpdatedAt,
+		})
+	}
+
+	return stockResponses, nil
+}
+
+// GetAllStockTransfers godoc
+// @Summary      Get all stock transfers
+// @Description  Get stock transfers between warehouses, newest first
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Param product_id query int false "Search by product ID"
+// @Success      200 {object} dtos.GetAllStockTransferStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/stock-transfers [get]
+// @Security BearerAuth
+func (u *warehouseUsecase) GetAllStockTransfers(ctx context.Context, page, limit int, productID uint) ([]dtos.StockTransferResponse, int, error) {
+	transfers, count, err := u.warehouseRepo.GetAllStockTransfers(ctx, page, limit, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transferResponses := []dtos.StockTransferResponse{}
+	for _, transfer := range transfers {
+		transferResponses = append(transferResponses, toStockTransferResponse(transfer))
+	}
+
+	return transferResponses, count, nil
+}
+
+// TransferStock godoc
+// @Summary      Transfer stock
+// @Description  Move stock of a product between warehouses. A from_warehouse_id of 0 takes the stock from the unassigned stock of the product
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.StockTransferInput true "Payload Body [RAW]"
+// @Success      201 {object} dtos.StockTransferCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /admin/stock-transfers [post]
+// @Security BearerAuth
+func (u *warehouseUsecase) TransferStock(ctx context.Context, userID uint, input dtos.StockTransferInput) (dtos.StockTransferResponse, error) {
+	if input.Quantity <= 0 {
+		return dtos.StockTransferResponse{}, apperrors.Validation("Quantity must be positive")
+	}
+	if input.FromWarehouseID == input.ToWarehouseID {
+		return dtos.StockTransferResponse{}, apperrors.Validation("Source and destination warehouse must differ")
+	}
+	if _, err := u.productRepo.GetProductByID(ctx, input.ProductID); err != nil {
+		return dtos.StockTransferResponse{}, apperrors.NotFound("Product not found")
+	}
+	if input.FromWarehouseID != 0 {
+		if _, err := u.warehouseRepo.GetWarehouseByID(ctx, input.FromWarehouseID); err != nil {
+			return dtos.StockTransferResponse{}, apperrors.NotFound("Source warehouse not found")
+		}
+	}
+	if _, err := u.warehouseRepo.GetWarehouseByID(ctx, input.ToWarehouseID); err != nil {
+		return dtos.StockTransferResponse{}, apperrors.NotFound("Destination warehouse not found")
+	}
+
+	transfer, err := u.warehouseRepo.TransferStock(ctx, models.StockTransfer{
+		ProductID:       input.ProductID,
+		FromWarehouseID: input.FromWarehouseID,
+		ToWarehouseID:   input.ToWarehouseID,
+		Quantity:        input.Quantity,
+		UserID:          &userID,
+		Note:            input.Note,
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrInsufficientStock) {
+			return dtos.StockTransferResponse{}, apperrors.Conflict("Insufficient stock for product %d", input.ProductID)
+		}
+		return dtos.StockTransferResponse{}, err
+	}
+
+	return toStockTransferResponse(transfer), nil
+}
+
+func toWarehouseResponse(warehouse models.Warehouse) dtos.WarehouseResponse {
+	return dtos.WarehouseResponse{
+		WarehouseID: warehouse.ID,
+		Name:        warehouse.Name,
+		Address:     warehouse.Address,
+		Latitude:    warehouse.Latitude,
+		Longitude:   warehouse.Longitude,
+		CreatedAt:   warehouse.CreatedAt,
+		UpdatedAt:   warehouse.UpdatedAt,
+	}
+}
+
+func toStockTransferResponse(transfer models.StockTransfer) dtos.StockTransferResponse {
+	return dtos.StockTransferResponse{
+		StockTransferID: transfer.ID,
+		ProductID:       transfer.ProductID,
+		FromWarehouseID: transfer.FromWarehouseID,
+		ToWarehouseID:   transfer.ToWarehouseID,
+		Quantity:        transfer.Quantity,
+		UserID:          transfer.UserID,
+		Note:            transfer.Note,
+		CreatedAt:       transfer.CreatedAt,
+	}
+}
@@ -0,0 +1,335 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"synapsis-backend/apperrors"
+	"synapsis-backend/dtos"
+	"synapsis-backend/models"
+	"synapsis-backend/repositories"
+
+	"gorm.io/gorm"
+)
+
+type WishlistUsecase interface {
+	GetAllWishlists(ctx context.Context, page, limit int, userID uint) ([]dtos.WishlistResponse, int, error)
+	GetWishlistByID(ctx context.Context, userID, id uint) (dtos.WishlistResponse, error)
+	CreateWishlist(ctx context.Context, userID uint, input dtos.WishlistInput) (dtos.WishlistResponse, error)
+	UpdateWishlist(ctx context.Context, userID, id uint, input dtos.WishlistUpdateInput) (dtos.WishlistResponse, error)
+	DeleteWishlist(ctx context.Context, userID, id uint) error
+	MoveToCart(ctx context.Context, userID, id uint) (dtos.CartResponse, error)
+	SaveForLater(ctx context.Context, userID, cartID uint) (dtos.WishlistResponse, error)
+}
+
+type wishlistUsecase struct {
+	wishlistRepo repositories.WishlistRepository
+	cartRepo     repositories.CartRepository
+	productRepo  repositories.ProductRepository
+}
+
+func NewWishlistUsecase(
+	WishlistRepo repositories.WishlistRepository,
+	CartRepo repositories.CartRepository,
+	ProductRepo repositories.ProductRepository,
+) WishlistUsecase {
+	return &wishlistUsecase{WishlistRepo, CartRepo, ProductRepo}
+}
+
+// GetAllWishlists godoc
+// @Summary      Get all wishlist
+// @Description  Get the wishlist of the logged in user
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success      200 {object} dtos.GetAllWishlistStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /wishlist [get]
+// @Security BearerAuth
+func (u *wishlistUsecase) GetAllWishlists(ctx context.Context, page, limit int, userID uint) ([]dtos.WishlistResponse, int, error) {
+	wishlists, count, err := u.wishlistRepo.GetAllWishlists(ctx, page, limit, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	wishlistResponses := []dtos.WishlistResponse{}
+	for _, wishlist := range wishlists {
+		wishlistResponse, err := u.toWishlistResponse(ctx, wishlist)
+		if err != nil {
+			return nil, 0, err
+		}
+		wishlistResponses = append(wishlistResponses, wishlistResponse)
+	}
+
+	return wishlistResponses, count, nil
+}
+
+// GetWishlistByID godoc
+// @Summary      Get wishlist by ID
+// @Description  Get wishlist by ID
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID wishlist"
+// @Success      200 {object} dtos.WishlistStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /wishlist/{id} [get]
+// @Security BearerAuth
+func (u *wishlistUsecase) GetWishlistByID(ctx context.Context, userID, id uint) (dtos.WishlistResponse, error) {
+	var wishlistResponse dtos.WishlistResponse
+
+	wishlist, err := u.getUserWishlist(ctx, userID, id)
+	if err != nil {
+		return wishlistResponse, err
+	}
+
+	return u.toWishlistResponse(ctx, wishlist)
+}
+
+// CreateWishlist godoc
+// @Summary      Add product to wishlist
+// @Description  Save a product to the wishlist of the logged in user
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.WishlistInput true "Payload Body [RAW]"
+// @Success      201 {object} dtos.WishlistCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /wishlist [post]
+// @Security BearerAuth
+func (u *wishlistUsecase) CreateWishlist(ctx context.Context, userID uint, input dtos.WishlistInput) (dtos.WishlistResponse, error) {
+	var wishlistResponse dtos.WishlistResponse
+
+	if _, err := u.productRepo.GetProductByID(ctx, input.ProductID); err != nil {
+		return wishlistResponse, apperrors.NotFound("Product not found")
+	}
+
+	existing, err := u.wishlistRepo.GetWishlistByUserAndProduct(ctx, userID, input.ProductID)
+	if err == nil && existing.ID > 0 {
+		return wishlistResponse, apperrors.Conflict("Product already in wishlist")
+	}
+
+	createdWishlist, err := u.wishlistRepo.CreateWishlist(ctx, models.Wishlist{
+		UserID:            userID,
+		ProductID:         input.ProductID,
+		NotifyWhenInStock: input.NotifyWhenInStock,
+	})
+	if err != nil {
+		return wishlistResponse, err
+	}
+
+	return u.toWishlistResponse(ctx, createdWishlist)
+}
+
+// UpdateWishlist godoc
+// @Summary      Update wishlist
+// @Description  Turn the back-in-stock notification of a wishlist line on or off
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID wishlist"
+// @Param        request body dtos.WishlistUpdateInput true "Payload Body [RAW]"
+// @Success      200 {object} dtos.WishlistStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      422 {object} dtos.UnprocessableEntityResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /wishlist/{id} [put]
+// @Security BearerAuth
+func (u *wishlistUsecase) UpdateWishlist(ctx context.Context, userID, id uint, input dtos.WishlistUpdateInput) (dtos.WishlistResponse, error) {
+	var wishlistResponse dtos.WishlistResponse
+
+	wishlist, err := u.getUserWishlist(ctx, userID, id)
+	if err != nil {
+		return wishlistResponse, err
+	}
+
+	wishlist.NotifyWhenInStock = input.NotifyWhenInStock
+	if input.NotifyWhenInStock {
+		wishlist.NotifiedAt = nil
+	}
+
+	wishlist, err = u.wishlistRepo.UpdateWishlist(ctx, wishlist)
+	if err != nil {
+		return wishlistResponse, err
+	}
+
+	return u.toWishlistResponse(ctx, wishlist)
+}
+
+// DeleteWishlist godoc
+// @Summary      Delete a wishlist
+// @Description  Remove a product from the wishlist of the logged in user
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID wishlist"
+// @Success      200 {object} dtos.StatusOKDeletedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /wishlist/{id} [delete]
+// @Security BearerAuth
+func (u *wishlistUsecase) DeleteWishlist(ctx context.Context, userID, id uint) error {
+	wishlist, err := u.getUserWishlist(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return u.wishlistRepo.DeleteWishlist(ctx, wishlist)
+}
+
+// MoveToCart godoc
+// @Summary      Move wishlist to cart
+// @Description  Move a product from the wishlist to the cart of the logged in user
+// @Tags         Wishlist
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID wishlist"
+// @Success      200 {object} dtos.CartStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      409 {object} dtos.ConflictResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /wishlist/{id}/move-to-cart [post]
+// @Security BearerAuth
+func (u *wishlistUsecase) MoveToCart(ctx context.Context, userID, id uint) (dtos.CartResponse, error) {
+	var cartResponse dtos.CartResponse
+
+	wishlist, err := u.getUserWishlist(ctx, userID, id)
+	if err != nil {
+		return cartResponse, err
+	}
+
+	product, err := u.productRepo.GetProductByID(ctx, wishlist.ProductID)
+	if err != nil {
+		return cartResponse, apperrors.NotFound("Product not found")
+	}
+	if !product.Status {
+		return cartResponse, apperrors.Conflict("Product is not available")
+	}
+
+	// Merge into the existing cart line for the product, if there is one.
+	cart := models.Cart{UserID: userID, ProductID: product.ID}
+	carts, err := u.cartRepo.GetCartsByUserID(ctx, userID)
+	if err != nil {
+		return cartResponse, err
+	}
+	for _, existing := range carts {
+		if existing.ProductID == product.ID {
+			cart = existing
+			break
+		}
+	}
+	cart.Quantity++
+	cart.Price = product.Price * cart.Quantity
+
+	if product.Stock < cart.Quantity {
+		return cartResponse, apperrors.Conflict("Product is out of stock")
+	}
+
+	cart, err = u.wishlistRepo.MoveToCart(ctx, wishlist, cart)
+	if err != nil {
+		return cartResponse, err
+	}
+
+	cartResponse = dtos.CartResponse{
+		CartID:    cart.ID,
+		UserID:    cart.UserID,
+		ProductID: cart.ProductID,
+		Quantity:  cart.Quantity,
+		Price:     cart.Price,
+		CreatedAt: cart.CreatedAt,
+		UpdatedAt: cart.UpdatedAt,
+	}
+	return cartResponse, nil
+}
+
+// SaveForLater godoc
+// @Summary      Save cart for later
+// @Description  Move a product from the cart to the wishlist of the logged in user
+// @Tags         Cart
+// @Accept       json
+// @Produce      json
+// @Param id path integer true "ID cart"
+// @Success      200 {object} dtos.WishlistStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /cart/{id}/save-for-later [post]
+// @Security BearerAuth
+func (u *wishlistUsecase) SaveForLater(ctx context.Context, userID, cartID uint) (dtos.WishlistResponse, error) {
+	var wishlistResponse dtos.WishlistResponse
+
+	cart, err := u.cartRepo.GetCartByID(ctx, cartID)
+	if err != nil || cart.UserID != userID {
+		return wishlistResponse, apperrors.NotFound("Cart not found")
+	}
+
+	wishlist, err := u.wishlistRepo.GetWishlistByUserAndProduct(ctx, userID, cart.ProductID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return wishlistResponse, err
+		}
+		wishlist = models.Wishlist{UserID: userID, ProductID: cart.ProductID}
+	}
+
+	wishlist, err = u.wishlistRepo.SaveForLater(ctx, cart, wishlist)
+	if err != nil {
+		return wishlistResponse, err
+	}
+
+	return u.toWishlistResponse(ctx, wishlist)
+}
+
+func (u *wishlistUsecase) getUserWishlist(ctx context.Context, userID, id uint) (models.Wishlist, error) {
+	wishlist, err := u.wishlistRepo.GetWishlistByID(ctx, id)
+	if err != nil || wishlist.UserID != userID {
+		return wishlist, apperrors.NotFound("Wishlist not found")
+	}
+	return wishlist, nil
+}
+
+func (u *wishlistUsecase) toWishlistResponse(ctx context.Context, wishlist models.Wishlist) (dtos.WishlistResponse, error) {
+	wishlistResponse := dtos.WishlistResponse{
+		WishlistID:        wishlist.ID,
+		UserID:            wishlist.UserID,
+		ProductID:         wishlist.ProductID,
+		NotifyWhenInStock: wishlist.NotifyWhenInStock,
+		NotifiedAt:        wishlist.NotifiedAt,
+		CreatedAt:         wishlist.CreatedAt,
+		UpdatedAt:         wishlist.UpdatedAt,
+	}
+
+	product, err := u.productRepo.GetProductByID(ctx, wishlist.ProductID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return wishlistResponse, err
+	}
+	wishlistResponse.ProductName = product.Name
+	wishlistResponse.Price = product.Price
+	wishlistResponse.Stock = product.Stock
+
+	return wishlistResponse, nil
+}
@@ -0,0 +1,60 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"synapsis-backend/usecases"
+	"sync"
+	"time"
+)
+
+// LowStockMonitor periodically checks every product against its reorder
+// threshold, on top of the check done on every stock movement.
+type LowStockMonitor struct {
+	inventoryUsecase usecases.InventoryUsecase
+	interval         time.Duration
+	log              *slog.Logger
+
+	mu          sync.Mutex
+	lastCheckAt time.Time
+	lastErr     error
+}
+
+func NewLowStockMonitor(inventoryUsecase usecases.InventoryUsecase, interval time.Duration, log *slog.Logger) *LowStockMonitor {
+	return &LowStockMonitor{inventoryUsecase: inventoryUsecase, interval: interval, log: log}
+}
+
+func (m *LowStockMonitor) Name() string {
+	return "low_stock_monitor"
+}
+
+// LastCheck returns when stock levels were last checked and how it went.
+func (m *LowStockMonitor) LastCheck() (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastCheckAt, m.lastErr
+}
+
+// Run checks the stock levels every interval until ctx is done.
+func (m *LowStockMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		err := m.inventoryUsecase.CheckLowStock(ctx)
+		// A check cut short by shutdown is not worth an error.
+		if err != nil && ctx.Err() == nil {
+			m.log.ErrorContext(ctx, "failed to check low stock", slog.String("worker", m.Name()), slog.Any("error", err))
+		}
+
+		m.mu.Lock()
+		m.lastCheckAt, m.lastErr = time.Now(), err
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
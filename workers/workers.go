@@ -0,0 +1,107 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Worker is a background job that runs until its context is done.
+type Worker interface {
+	Name() string
+	Run(ctx context.Context)
+}
+
+// Checker is implemented by workers that run a check periodically, to
+// report the outcome of the last one.
+type Checker interface {
+	LastCheck() (time.Time, error)
+}
+
+// Status is the state of a worker of a Group.
+type Status struct {
+	Name      string
+	Running   bool
+	LastRunAt *time.Time
+	LastError string
+}
+
+// Group runs workers in the background and waits for them to stop.
+type Group struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	workers []Worker
+	running map[string]bool
+}
+
+// Add adds a worker to be started by Run.
+func (g *Group) Add(worker Worker) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.workers = append(g.workers, worker)
+}
+
+// Run starts every worker. They run until ctx is done.
+func (g *Group) Run(ctx context.Context) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running == nil {
+		g.running = map[string]bool{}
+	}
+
+	for _, worker := range g.workers {
+		worker := worker
+		g.running[worker.Name()] = true
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			defer g.setRunning(worker.Name(), false)
+			worker.Run(ctx)
+		}()
+	}
+}
+
+func (g *Group) setRunning(name string, running bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.running[name] = running
+}
+
+// Statuses reports the state of every worker.
+func (g *Group) Statuses() []Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	statuses := make([]Status, 0, len(g.workers))
+	for _, worker := range g.workers {
+		status := Status{Name: worker.Name(), Running: g.running[worker.Name()]}
+		if checker, ok := worker.(Checker); ok {
+			lastRunAt, err := checker.LastCheck()
+			if !lastRunAt.IsZero() {
+				status.LastRunAt = &lastRunAt
+			}
+			if err != nil {
+				status.LastError = err.Error()
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Wait waits until every worker has returned or ctx is done, whichever
+// comes first, and returns ctx.Err() in the latter case.
+func (g *Group) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}